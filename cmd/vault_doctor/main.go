@@ -4,7 +4,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/raymonepping/vault_doctor/internal/medic"
 	"github.com/raymonepping/vault_doctor/internal/version"
@@ -58,6 +60,22 @@ func main() {
 		runCompletionCmd()
 		return
 
+	case "schema":
+		medic.PrintSchema()
+		return
+
+	case "diff":
+		runDiffCmd()
+		return
+
+	case "bundle":
+		runBundleCmd()
+		return
+
+	case "probe":
+		runProbeCmd()
+		return
+
 	default:
 		fmt.Print(medic.Doc(resolvedVersion()))
 		return
@@ -73,22 +91,157 @@ func quote(s string) string {
 
 func runMedicCmd() {
 	fs := flag.NewFlagSet("medic", flag.ExitOnError)
-	jsonOut := fs.Bool("json", false, "Output JSON")
+	jsonOut := fs.Bool("json", false, "Output JSON (alias for --format json)")
+	format := fs.String("format", "pretty", "Output format: "+strings.Join(medic.Formats, "|"))
+	templatePath := fs.String("template", "", "text/template file for --format template")
 	quiet := fs.Bool("quiet", false, "Quiet mode")
 	noColor := fs.Bool("no-color", false, "Disable colors")
+	dataPath := fs.String("data-path", "", "Vault data directory, to mark the disk that holds it")
+	clientLimit := fs.Int("client-limit", 0, "Licensed client count for utilisation checks (default: from the license)")
+	recursiveNS := fs.Bool("recursive-namespaces", false, "Run namespace diagnostics in every child namespace")
+	kvMaxVersions := fs.Int("kv-max-versions", 0, "Flag KV v2 mounts keeping more versions than this")
+	kvCASMounts := fs.String("kv-cas-mounts", "", "Comma-separated mount globs that must have cas_required")
+	kvDeleteAfterMax := fs.Duration("kv-delete-after-max", 0, "Flag KV v2 mounts without delete_version_after <= this")
+	suppressRules := fs.String("suppress-rules", "", "Comma-separated config rule IDs to skip")
+	baselineFile := fs.String("baseline", "", "Fail on drift from this baseline file")
+	saveBaseline := fs.String("save-baseline", "", "Write the cluster shape to this baseline file")
+	anonymize := fs.Bool("anonymize", false, "Replace hostnames, IPs, cluster, namespace and mount names with pseudonyms")
+	warn := fs.String("warn", "", "Nagios warning thresholds, e.g. latency=250,token_ttl=86400:")
+	crit := fs.String("crit", "", "Nagios critical thresholds, e.g. latency=1000,token_ttl=3600:")
+	nagiosWarnDiags := fs.Bool("nagios-warn-diagnostics", false, "Let warning diagnostics raise the Nagios state to WARNING")
+	notifyWebhook := fs.String("notify-webhook", "", "POST a run summary to this webhook URL")
+	notifyPreset := fs.String("notify-preset", "auto", "Webhook payload: "+strings.Join(medic.NotifyPresets, "|"))
+	notifyOnChange := fs.Bool("notify-on-change", false, "Only notify when the status changed since the last run")
+	notifyState := fs.String("notify-state", "", "File remembering the last status (default in the user cache dir)")
 	_ = fs.Parse(os.Args[2:])
 
+	*format = strings.ToLower(strings.TrimSpace(*format))
+	if *templatePath != "" && *format == "pretty" {
+		*format = "template"
+	}
+	if !medic.ValidFormat(*format) {
+		fmt.Fprintf(os.Stderr, "Unsupported format: %s (use %s)\n", *format, strings.Join(medic.Formats, "|"))
+		os.Exit(2)
+	}
+	*notifyPreset = strings.ToLower(strings.TrimSpace(*notifyPreset))
+	if !slices.Contains(medic.NotifyPresets, *notifyPreset) {
+		fmt.Fprintf(os.Stderr, "Unsupported notify preset: %s (use %s)\n", *notifyPreset, strings.Join(medic.NotifyPresets, "|"))
+		os.Exit(2)
+	}
+
 	opt := medic.Options{
-		Version: resolvedVersion(),
-		Quiet:   *quiet,
-		JSON:    *jsonOut,
-		NoColor: *noColor,
+		Version:     resolvedVersion(),
+		Quiet:       *quiet,
+		JSON:        *jsonOut,
+		Format:      *format,
+		Template:    *templatePath,
+		NoColor:     *noColor,
+		ClientLimit: *clientLimit,
+		DataPath:    *dataPath,
+
+		RecursiveNamespaces: *recursiveNS,
+
+		KVMaxVersions:    *kvMaxVersions,
+		KVCASMounts:      splitList(*kvCASMounts),
+		KVDeleteAfterMax: *kvDeleteAfterMax,
+
+		SuppressRules: splitList(*suppressRules),
+
+		Baseline:     *baselineFile,
+		SaveBaseline: *saveBaseline,
+
+		Anonymize: *anonymize,
+
+		Warn: splitList(*warn),
+		Crit: splitList(*crit),
+
+		NagiosWarnDiagnostics: *nagiosWarnDiags,
+
+		NotifyWebhook:  *notifyWebhook,
+		NotifyPreset:   *notifyPreset,
+		NotifyOnChange: *notifyOnChange,
+		NotifyState:    *notifyState,
 	}
 
 	code := medic.Run(opt)
 	os.Exit(code)
 }
 
+// splitList turns "a, b,,c" into [a b c].
+func splitList(s string) []string {
+	out := []string{}
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func runDiffCmd() {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := fs.String("format", "pretty", "Output format: "+strings.Join(medic.DiffFormats, "|"))
+	noColor := fs.Bool("no-color", false, "Disable colors")
+	_ = fs.Parse(os.Args[2:])
+
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: vault_doctor diff [--format pretty|json|markdown] [--no-color] before.json after.json")
+		os.Exit(2)
+	}
+	*format = strings.ToLower(strings.TrimSpace(*format))
+	if !slices.Contains(medic.DiffFormats, *format) {
+		fmt.Fprintf(os.Stderr, "Unsupported format: %s (use %s)\n", *format, strings.Join(medic.DiffFormats, "|"))
+		os.Exit(2)
+	}
+
+	opt := medic.Options{
+		Version: resolvedVersion(),
+		Format:  *format,
+		NoColor: *noColor,
+	}
+	os.Exit(medic.Diff(fs.Arg(0), fs.Arg(1), opt))
+}
+
+func runBundleCmd() {
+	fs := flag.NewFlagSet("bundle", flag.ExitOnError)
+	out := fs.String("o", "", "Output file (default vault-support-<timestamp>.tar.gz)")
+	fs.StringVar(out, "output", "", "Output file (alias for -o)")
+	anonymize := fs.Bool("anonymize", false, "Replace hostnames, IPs, cluster, namespace and mount names with pseudonyms")
+	quiet := fs.Bool("quiet", false, "Only report errors")
+	noColor := fs.Bool("no-color", false, "Disable colors")
+	_ = fs.Parse(os.Args[2:])
+
+	opt := medic.Options{
+		Version:   resolvedVersion(),
+		Quiet:     *quiet,
+		NoColor:   *noColor,
+		Anonymize: *anonymize,
+	}
+	os.Exit(medic.Bundle(*out, opt))
+}
+
+func runProbeCmd() {
+	fs := flag.NewFlagSet("probe", flag.ExitOnError)
+	expect := fs.String("expect", "unsealed", "Expected node state: "+strings.Join(medic.ProbeExpectations, "|"))
+	listen := fs.String("listen", "", "Serve the probe over HTTP on this address (e.g. :8080)")
+	timeout := fs.Duration("timeout", 2*time.Second, "Timeout for the health call")
+	verbose := fs.Bool("verbose", false, "Print the outcome")
+	fs.BoolVar(verbose, "v", false, "Print the outcome (alias for --verbose)")
+	_ = fs.Parse(os.Args[2:])
+
+	*expect = strings.ToLower(strings.TrimSpace(*expect))
+	if !slices.Contains(medic.ProbeExpectations, *expect) {
+		fmt.Fprintf(os.Stderr, "Unsupported expectation: %s (use %s)\n", *expect, strings.Join(medic.ProbeExpectations, "|"))
+		os.Exit(2)
+	}
+	os.Exit(medic.Probe(medic.ProbeOptions{
+		Expect:  *expect,
+		Listen:  *listen,
+		Timeout: *timeout,
+		Verbose: *verbose,
+	}))
+}
+
 func runCompletionCmd() {
 	args := os.Args[2:]
 	if len(args) < 1 {
@@ -0,0 +1,128 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const approleRoleLimit = 200 // roles audited per AppRole mount
+
+type approleRoleResp struct {
+	Data struct {
+		BindSecretID         bool     `json:"bind_secret_id"`
+		SecretIDTTL          int64    `json:"secret_id_ttl"`
+		SecretIDNumUses      int      `json:"secret_id_num_uses"`
+		SecretIDBoundCIDRs   []string `json:"secret_id_bound_cidrs"`
+		TokenBoundCIDRs      []string `json:"token_bound_cidrs"`
+		TokenNoDefaultPolicy bool     `json:"token_no_default_policy"`
+		TokenPolicies        []string `json:"token_policies"`
+		Policies             []string `json:"policies"`
+		TokenTTL             int64    `json:"token_ttl"`
+		TokenMaxTTL          int64    `json:"token_max_ttl"`
+		TokenType            string   `json:"token_type"`
+	} `json:"data"`
+}
+
+// auditAppRole returns the risky settings of one AppRole role.
+func auditAppRole(r approleRoleResp) []string {
+	d := r.Data
+	findings := []string{}
+	if d.BindSecretID {
+		if d.SecretIDTTL == 0 {
+			findings = append(findings, "secret_id_ttl unbounded")
+		}
+		if d.SecretIDNumUses == 0 {
+			findings = append(findings, "secret_id_num_uses=0 (unlimited)")
+		}
+	}
+	if len(d.TokenBoundCIDRs) == 0 && len(d.SecretIDBoundCIDRs) == 0 {
+		if !d.BindSecretID {
+			findings = append(findings, "bind_secret_id=false without CIDR binding (role_id alone logs in)")
+		} else {
+			findings = append(findings, "no token_bound_cidrs or secret_id_bound_cidrs")
+		}
+	}
+	if d.TokenNoDefaultPolicy && len(d.TokenPolicies) == 0 && len(d.Policies) == 0 {
+		findings = append(findings, "token_no_default_policy with no other policies (tokens get no policies)")
+	}
+	return findings
+}
+
+// AppRole roles under one mount.
+func approleDiagnostics(client *http.Client, cfg Config, mount string) []check {
+	diagnostics := []check{}
+	base := "/v1/auth/" + strings.TrimSuffix(mount, "/") + "/role"
+	var lr listResp
+	code, err := doLIST(client, cfg, base, &lr)
+	if err != nil || code != 200 {
+		if code == 403 {
+			diagnostics = append(diagnostics, itemCheck("auth.approle", mount, "AppRole "+mount, true, "forbidden (insufficient perms)"))
+		}
+		return diagnostics
+	}
+	sort.Strings(lr.Data.Keys)
+	flagged := 0
+	for i, name := range lr.Data.Keys {
+		if i >= approleRoleLimit {
+			diagnostics = append(diagnostics, itemCheck("auth.approle", mount, "AppRole "+mount, true, fmt.Sprintf("%d more role(s) not audited", len(lr.Data.Keys)-approleRoleLimit)))
+			break
+		}
+		var r approleRoleResp
+		if code, err := doGET(client, cfg, base+"/"+name, &r); err != nil || code != 200 {
+			continue
+		}
+		detail := fmt.Sprintf("token_ttl=%s/%s secret_id_ttl=%s uses=%d",
+			ttlOrDefault(r.Data.TokenTTL), ttlOrDefault(r.Data.TokenMaxTTL), humanTTL(r.Data.SecretIDTTL), r.Data.SecretIDNumUses)
+		findings := auditAppRole(r)
+		if len(findings) > 0 {
+			flagged++
+			detail += " — " + strings.Join(findings, "; ")
+		}
+		diagnostics = append(diagnostics, itemCheck("auth.approle", mount+name, "AppRole "+mount+name, len(findings) == 0, detail))
+	}
+	if flagged > 0 {
+		extraHints = append(extraHints, fmt.Sprintf("%d AppRole role(s) on auth/%s have risky settings; bound secret_id_ttl/num_uses and add CIDR bindings.", flagged, mount))
+	}
+	return diagnostics
+}
+
+// Auth methods + tuning, with an AppRole role audit.
+// Returns the diagnostics and the number of enabled methods.
+func authDiagnostics(client *http.Client, cfg Config) ([]check, int) {
+	diagnostics := []check{}
+	var a mountsResp
+	cnt := 0
+	if code, err := doGET(client, cfg, "/v1/sys/auth", &a); err == nil && code == 200 {
+		paths := make([]string, 0, len(a.Data))
+		for p := range a.Data {
+			if p != "" {
+				cnt++
+				paths = append(paths, p)
+				seenAuthMounts = append(seenAuthMounts, p)
+			}
+		}
+		diagnostics = append(diagnostics, check{"auth.methods", "Auth methods", true, fmt.Sprintf("%d", cnt), map[string]any{"count": cnt}})
+
+		sort.Strings(paths)
+		for _, p := range paths {
+			m := a.Data[p]
+			detail := fmt.Sprintf("type=%s accessor=%s ttl=%s/%s",
+				m.Type, m.Accessor, ttlOrDefault(m.Config.DefaultLeaseTTL), ttlOrDefault(m.Config.MaxLeaseTTL))
+			if m.Config.TokenType != "" {
+				detail += " token_type=" + m.Config.TokenType
+			}
+			if m.Local {
+				detail += " local"
+			}
+			diagnostics = append(diagnostics, itemCheck("auth.method", p, "Auth "+p, true, detail))
+			if m.Type == "approle" {
+				diagnostics = append(diagnostics, approleDiagnostics(client, cfg, p)...)
+			}
+		}
+	} else if code == 403 {
+		diagnostics = append(diagnostics, check{"auth.methods", "Auth methods", true, "forbidden (insufficient perms)", nil})
+	}
+	return diagnostics, cnt
+}
@@ -0,0 +1,242 @@
+package medic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const baselineVersion = 1
+
+// baseline is the expected shape of a cluster. A nil map/slice means the
+// category was not readable when captured and is not compared.
+type baseline struct {
+	BaselineVersion int               `json:"baseline_version"`
+	CreatedAt       string            `json:"created_at"`
+	Addr            string            `json:"vault_addr"`
+	Namespace       string            `json:"namespace,omitempty"`
+	ClusterName     string            `json:"cluster_name,omitempty"`
+	Mounts          map[string]string `json:"mounts"`       // path -> type
+	AuthMethods     map[string]string `json:"auth_methods"` // path -> type
+	AuditDevices    map[string]string `json:"audit_devices"`
+	Policies        []string          `json:"policies"`
+	SealType        string            `json:"seal_type"`
+	Replication     string            `json:"replication"`
+	NodeCount       *int              `json:"node_count"`
+}
+
+// pathTypes reads a sys/mounts-shaped endpoint into path -> type.
+func pathTypes(client *http.Client, cfg Config, path string) map[string]string {
+	var m mountsResp
+	code, err := doGET(client, cfg, path, &m)
+	if err != nil || code != 200 {
+		return nil
+	}
+	out := map[string]string{}
+	for p, e := range m.Data {
+		if p != "" {
+			out[p] = e.Type
+		}
+	}
+	return out
+}
+
+func replicationMode(health *healthResp) string {
+	if health == nil {
+		return ""
+	}
+	dr, perf := health.ReplicationDR, health.ReplicationPerf
+	if dr == "" && health.ReplicationDRLegacy != nil {
+		dr = health.ReplicationDRLegacy.Mode
+	}
+	if perf == "" && health.ReplicationPerfLegacy != nil {
+		perf = health.ReplicationPerfLegacy.Mode
+	}
+	if dr == "" && perf == "" {
+		return ""
+	}
+	return fmt.Sprintf("dr=%s perf=%s", orDisabled(dr), orDisabled(perf))
+}
+
+func orDisabled(s string) string {
+	if s == "" {
+		return "disabled"
+	}
+	return s
+}
+
+func captureBaseline(client *http.Client, cfg Config, health *healthResp) baseline {
+	b := baseline{
+		BaselineVersion: baselineVersion,
+		CreatedAt:       time.Now().UTC().Format(time.RFC3339),
+		Addr:            cfg.Addr,
+		Namespace:       cfg.Namespace,
+		Mounts:          pathTypes(client, cfg, "/v1/sys/mounts"),
+		AuthMethods:     pathTypes(client, cfg, "/v1/sys/auth"),
+		AuditDevices:    pathTypes(client, cfg, "/v1/sys/audit"),
+		Replication:     replicationMode(health),
+	}
+	if health != nil {
+		b.ClusterName = health.ClusterName
+	}
+
+	var lr listResp
+	if code, err := doLIST(client, cfg, "/v1/sys/policies/acl", &lr); err == nil && code == 200 {
+		b.Policies = append([]string{}, lr.Data.Keys...)
+		sort.Strings(b.Policies)
+	}
+
+	var ss sealStatusResp
+	if code, err := doGET(client, cfg, "/v1/sys/seal-status", &ss); err == nil && code == 200 {
+		b.SealType = ss.Type
+	}
+
+	var rc raftConfigResp
+	if code, err := doGET(client, cfg, "/v1/sys/storage/raft/configuration", &rc); err == nil && code == 200 {
+		n := len(rc.Data.Config.Servers)
+		b.NodeCount = &n
+	}
+	return b
+}
+
+func saveBaseline(path string, b baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+func loadBaseline(path string) (*baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if b.BaselineVersion == 0 || b.BaselineVersion > baselineVersion {
+		return nil, fmt.Errorf("%s: unsupported baseline_version %d", path, b.BaselineVersion)
+	}
+	return &b, nil
+}
+
+// driftMap describes added, removed and re-typed paths.
+func driftMap(want, got map[string]string) []string {
+	out := []string{}
+	keys := make([]string, 0, len(want)+len(got))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w, inWant := want[k]
+		g, inGot := got[k]
+		switch {
+		case !inWant:
+			out = append(out, fmt.Sprintf("+%s (%s)", k, g))
+		case !inGot:
+			out = append(out, fmt.Sprintf("-%s (%s)", k, w))
+		case w != g:
+			out = append(out, fmt.Sprintf("~%s (%s→%s)", k, w, g))
+		}
+	}
+	return out
+}
+
+func driftList(want, got []string) []string {
+	out := []string{}
+	for _, p := range got {
+		if !slices.Contains(want, p) {
+			out = append(out, "+"+p)
+		}
+	}
+	for _, p := range want {
+		if !slices.Contains(got, p) {
+			out = append(out, "-"+p)
+		}
+	}
+	return out
+}
+
+// compareBaseline turns drift into failing results, one per category.
+func compareBaseline(want, got baseline) []check {
+	results := []check{}
+	drifted := []string{}
+
+	set := func(id, name string, wantNil, gotNil bool, drift []string) {
+		switch {
+		case wantNil:
+			results = append(results, check{id, name, true, "not in baseline", nil})
+		case gotNil:
+			results = append(results, check{id, name, true, "not readable (insufficient perms)", nil})
+		case len(drift) == 0:
+			results = append(results, check{id, name, true, "no drift", nil})
+		default:
+			results = append(results, check{id, name, false, strings.Join(drift, ", "), nil})
+			drifted = append(drifted, strings.ToLower(strings.TrimPrefix(name, "Baseline ")))
+		}
+	}
+	value := func(id, name, w, g string, known bool) {
+		d := []string{}
+		if w != g {
+			d = append(d, fmt.Sprintf("%s → %s", orNone(w), orNone(g)))
+		}
+		set(id, name, !known, false, d)
+	}
+
+	set("baseline.mounts", "Baseline mounts", want.Mounts == nil, got.Mounts == nil, driftMap(want.Mounts, got.Mounts))
+	set("baseline.auth_methods", "Baseline auth methods", want.AuthMethods == nil, got.AuthMethods == nil, driftMap(want.AuthMethods, got.AuthMethods))
+	set("baseline.audit_devices", "Baseline audit devices", want.AuditDevices == nil, got.AuditDevices == nil, driftMap(want.AuditDevices, got.AuditDevices))
+	set("baseline.policies", "Baseline policies", want.Policies == nil, got.Policies == nil, driftList(want.Policies, got.Policies))
+	value("baseline.seal_type", "Baseline seal type", want.SealType, got.SealType, want.SealType != "")
+	value("baseline.replication", "Baseline replication", orDisabled(want.Replication), orDisabled(got.Replication), true)
+	if want.NodeCount != nil && got.NodeCount != nil {
+		value("baseline.node_count", "Baseline node count", optInt(want.NodeCount), optInt(got.NodeCount), true)
+	}
+
+	if len(drifted) > 0 {
+		extraHints = append(extraHints, fmt.Sprintf("Drift from baseline (%s): confirm the change was intended, then refresh with --save-baseline.", strings.Join(drifted, ", ")))
+	}
+	return results
+}
+
+// baselineResults compares against --baseline and/or writes --save-baseline.
+func baselineResults(client *http.Client, cfg Config, health *healthResp, opt Options) []check {
+	if opt.Baseline == "" && opt.SaveBaseline == "" {
+		return nil
+	}
+	results := []check{}
+	st := startStep("checks", "Baseline", 0)
+	defer func() { st.done(results) }()
+	current := captureBaseline(client, cfg, health)
+
+	if opt.Baseline != "" {
+		want, err := loadBaseline(opt.Baseline)
+		if err != nil {
+			results = append(results, check{"baseline.file", "Baseline", false, err.Error(), nil})
+		} else {
+			results = append(results, check{"baseline.file", "Baseline", true, fmt.Sprintf("%s (captured %s)", opt.Baseline, want.CreatedAt), nil})
+			results = append(results, compareBaseline(*want, current)...)
+		}
+	}
+	if opt.SaveBaseline != "" {
+		if err := saveBaseline(opt.SaveBaseline, current); err != nil {
+			results = append(results, check{"baseline.saved", "Baseline saved", false, err.Error(), nil})
+		} else {
+			results = append(results, check{"baseline.saved", "Baseline saved", true, opt.SaveBaseline, nil})
+		}
+	}
+	return results
+}
@@ -0,0 +1,255 @@
+package medic
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const bundleVersion = 1
+
+// Endpoints collected into a support bundle, one file each. alt is tried
+// when the first path answers 400 (metrics without prometheus_retention_time).
+var bundleEndpoints = []struct {
+	file, path       string
+	altFile, altPath string
+}{
+	{"health.json", "/v1/sys/health?standbyok=true&perfstandbyok=true&sealedcode=200&uninitcode=200", "", ""},
+	{"seal-status.json", "/v1/sys/seal-status", "", ""},
+	{"leader.json", "/v1/sys/leader", "", ""},
+	{"config-state.json", "/v1/sys/config/state/sanitized", "", ""},
+	{"host-info.json", "/v1/sys/host-info", "", ""},
+	{"replication-status.json", "/v1/sys/replication/status", "", ""},
+	{"raft-configuration.json", "/v1/sys/storage/raft/configuration", "", ""},
+	{"raft-autopilot.json", "/v1/sys/storage/raft/autopilot/state", "", ""},
+	{"metrics.prom", "/v1/sys/metrics?format=prometheus", "metrics.json", "/v1/sys/metrics"},
+	{"plugins-catalog.json", "/v1/sys/plugins/catalog", "", ""},
+	{"mounts.json", "/v1/sys/mounts", "", ""},
+	{"auth.json", "/v1/sys/auth", "", ""},
+	{"audit.json", "/v1/sys/audit", "", ""},
+}
+
+// One manifest row per collected item.
+type bundleItem struct {
+	File       string `json:"file"`
+	Endpoint   string `json:"endpoint,omitempty"`
+	Status     string `json:"status"` // ok|forbidden|not_found|error
+	HTTPStatus int    `json:"http_status,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Bytes      int    `json:"bytes,omitempty"`
+}
+
+type bundleManifest struct {
+	BundleVersion int          `json:"bundle_version"`
+	CreatedAt     string       `json:"created_at"`
+	Version       string       `json:"vault_doctor_version"`
+	Addr          string       `json:"vault_addr"`
+	Anonymized    bool         `json:"anonymized"`
+	MedicExit     int          `json:"medic_exit_code"`
+	Items         []bundleItem `json:"items"`
+}
+
+type bundleFile struct {
+	name string
+	body []byte
+}
+
+func itemStatus(code int, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case code == http.StatusForbidden:
+		return "forbidden"
+	case code == http.StatusNotFound:
+		return "not_found"
+	case code >= 200 && code <= 299:
+		return "ok"
+	}
+	return "error"
+}
+
+// redactJSON scrubs a raw JSON body; bodies that are not JSON are scrubbed
+// as text.
+func (r *redactor) redactJSON(body []byte) []byte {
+	var tree any
+	if err := json.Unmarshal(body, &tree); err != nil {
+		return []byte(r.str(string(body)))
+	}
+	out, err := json.MarshalIndent(r.walk(tree), "", "  ")
+	if err != nil {
+		return []byte(r.str(string(body)))
+	}
+	return append(out, '\n')
+}
+
+var promLabel = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)="((?:[^"\\]|\\.)*)"`)
+
+// redactMetrics scrubs label values in Prometheus text, and learned names
+// (the hostname Vault puts in metric names) elsewhere; values are kept, so
+// the metrics stay usable.
+func (r *redactor) redactMetrics(body []byte) []byte {
+	lines := strings.Split(string(body), "\n")
+	for i, l := range lines {
+		open, end := strings.IndexByte(l, '{'), strings.LastIndexByte(l, '}')
+		if strings.HasPrefix(l, "#") || open < 0 || end < open {
+			lines[i] = r.scrubTerms(l)
+			continue
+		}
+		labels := promLabel.ReplaceAllStringFunc(l[open:end+1], func(m string) string {
+			sub := promLabel.FindStringSubmatch(m)
+			return sub[1] + `="` + r.str(sub[2]) + `"`
+		})
+		lines[i] = r.scrubTerms(l[:open]) + labels + l[end+1:]
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func writeBundle(path, dir string, files []bundleFile) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, bf := range files {
+		hdr := &tar.Header{Name: dir + "/" + bf.name, Mode: 0o600, Size: int64(len(bf.body)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			f.Close()
+			return err
+		}
+		if _, err := tw.Write(bf.body); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Bundle writes a redacted support bundle (tar.gz): the medic JSON report,
+// one file per endpoint and a manifest of what could be read. Exit codes:
+// 0 written, 2 config or write error.
+func Bundle(out string, opt Options) int {
+	runStarted = time.Now()
+	stamp := runStarted.UTC().Format("20060102-150405")
+	if out == "" {
+		out = "vault-support-" + stamp + ".tar.gz"
+	}
+	dir := "vault-support-" + stamp
+
+	loadDotEnvIfPresent(".env")
+	cfg := LoadConfigFromEnv()
+	if cfg.Addr == "" {
+		fmt.Fprintln(os.Stderr, "bundle: VAULT_ADDR not set")
+		return 2
+	}
+	client := NewHTTPClient(cfg.SkipVerify)
+	if cfg.Token == "" && cfg.RoleID != "" && cfg.SecretID != "" {
+		token, err := approleLogin(client, cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bundle: AppRole login: %v\n", err)
+			return 2
+		}
+		cfg.Token = token
+		registerSecret(token)
+	}
+	if cfg.Token == "" {
+		fmt.Fprintln(os.Stderr, "bundle: provide VAULT_TOKEN or VAULT_ROLE_ID + VAULT_SECRET_ID")
+		return 2
+	}
+
+	// the medic report first: it fills the globals the redactor learns from.
+	// Collecting a bundle must not post webhooks or write files besides it.
+	var report bytes.Buffer
+	reportOpt := opt
+	reportOpt.Format = "json"
+	reportOpt.Template = ""
+	reportOpt.SaveBaseline = ""
+	reportOpt.NotifyWebhook = ""
+	reportOpt.NotifyOnChange = false
+	reportOpt.NotifyState = ""
+	reportOut = &report
+	medicExit := run(cfg, reportOpt)
+	reportOut = os.Stdout
+	rd := lastRedactor
+
+	manifest := bundleManifest{
+		BundleVersion: bundleVersion,
+		CreatedAt:     runStarted.UTC().Format(time.RFC3339),
+		Version:       normVersion(opt.Version),
+		Addr:          rd.str(cfg.Addr),
+		Anonymized:    opt.Anonymize,
+		MedicExit:     medicExit,
+	}
+	files := []bundleFile{{"medic-report.json", report.Bytes()}}
+	manifest.Items = append(manifest.Items, bundleItem{File: "medic-report.json", Status: "ok", Bytes: report.Len()})
+
+	for _, ep := range bundleEndpoints {
+		file, path := ep.file, ep.path
+		code, body, err := doGETRaw(client, cfg, path)
+		if err == nil && code == http.StatusBadRequest && ep.altPath != "" {
+			file, path = ep.altFile, ep.altPath
+			code, body, err = doGETRaw(client, cfg, path)
+		}
+		item := bundleItem{File: file, Endpoint: path, Status: itemStatus(code, err), HTTPStatus: code}
+		if err != nil {
+			item.Error = rd.str(err.Error())
+		}
+		if item.Status == "ok" {
+			if strings.HasSuffix(file, ".prom") {
+				body = rd.redactMetrics(body)
+			} else {
+				body = rd.redactJSON(body)
+			}
+			item.Bytes = len(body)
+			files = append(files, bundleFile{file, body})
+		}
+		manifest.Items = append(manifest.Items, item)
+	}
+
+	var mb bytes.Buffer
+	enc := json.NewEncoder(&mb)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(manifest)
+	files = append(files, bundleFile{"manifest.json", mb.Bytes()})
+
+	if err := writeBundle(out, dir, files); err != nil {
+		fmt.Fprintf(os.Stderr, "bundle: %v\n", err)
+		return 2
+	}
+
+	rows := make([]check, 0, len(manifest.Items))
+	for _, it := range manifest.Items {
+		detail := it.Status
+		switch {
+		case it.Status == "ok":
+			detail = fmt.Sprintf("%s (%s)", it.Status, humanBytes(uint64(it.Bytes)))
+		case it.Error != "":
+			detail += ": " + it.Error
+		case it.HTTPStatus != 0:
+			detail += fmt.Sprintf(" (HTTP %d)", it.HTTPStatus)
+		}
+		rows = append(rows, check{name: it.File, ok: it.Status == "ok", detail: detail})
+	}
+	printSection("Support bundle", rows, opt)
+	if !opt.Quiet {
+		fmt.Printf("\nWrote %s\n", out)
+	}
+	return 0
+}
@@ -0,0 +1,224 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	clientTopNamespaces = 10 // per-namespace rows shown in diagnostics
+	clientTopMounts     = 3  // mounts listed per namespace row
+	clientLimitWarnPct  = 80 // utilisation (percent of the licensed clients) before we flag it
+)
+
+// The client entitlement of the loaded license, where the license reports one.
+type licenseClientsResp struct {
+	Data struct {
+		Autoloaded *struct {
+			Clients int `json:"clients"`
+		} `json:"autoloaded"`
+		PersistedAutoload *struct {
+			Clients int `json:"clients"`
+		} `json:"persisted_autoload"`
+	} `json:"data"`
+}
+
+// licensedClients returns the client limit: --client-limit when set,
+// otherwise the one in sys/license/status, otherwise 0 (unknown).
+func licensedClients(client *http.Client, cfg Config, opt Options) (int, string) {
+	if opt.ClientLimit > 0 {
+		return opt.ClientLimit, "--client-limit"
+	}
+	var lr licenseClientsResp
+	if code, err := doGET(client, cfg, "/v1/sys/license/status", &lr); err != nil || code != 200 {
+		return 0, ""
+	}
+	switch {
+	case lr.Data.Autoloaded != nil && lr.Data.Autoloaded.Clients > 0:
+		return lr.Data.Autoloaded.Clients, "license"
+	case lr.Data.PersistedAutoload != nil && lr.Data.PersistedAutoload.Clients > 0:
+		return lr.Data.PersistedAutoload.Clients, "license"
+	}
+	return 0, ""
+}
+
+// Counts as returned by the activity log. Older Vault versions report
+// distinct_entities/non_entity_tokens, newer ones entity_clients/non_entity_clients.
+type clientCounts struct {
+	Clients          int `json:"clients"`
+	DistinctEntities int `json:"distinct_entities"`
+	EntityClients    int `json:"entity_clients"`
+	NonEntityTokens  int `json:"non_entity_tokens"`
+	NonEntityClients int `json:"non_entity_clients"`
+	SecretSyncs      int `json:"secret_syncs"`
+	ACMEClients      int `json:"acme_clients"`
+}
+
+func (c clientCounts) entities() int  { return max(c.EntityClients, c.DistinctEntities) }
+func (c clientCounts) nonEntity() int { return max(c.NonEntityClients, c.NonEntityTokens) }
+
+func (c clientCounts) total() int {
+	if c.Clients > 0 {
+		return c.Clients
+	}
+	return c.entities() + c.nonEntity() + c.SecretSyncs + c.ACMEClients
+}
+
+func (c clientCounts) String() string {
+	return fmt.Sprintf("clients=%d (entity=%d, non-entity=%d, secret-sync=%d, acme=%d)",
+		c.total(), c.entities(), c.nonEntity(), c.SecretSyncs, c.ACMEClients)
+}
+
+type clientNamespace struct {
+	NamespaceID   string       `json:"namespace_id"`
+	NamespacePath string       `json:"namespace_path"`
+	Counts        clientCounts `json:"counts"`
+	Mounts        []struct {
+		MountPath string       `json:"mount_path"`
+		Counts    clientCounts `json:"counts"`
+	} `json:"mounts"`
+}
+
+type activityResp struct {
+	Data struct {
+		StartTime   string            `json:"start_time"`
+		EndTime     string            `json:"end_time"`
+		Total       clientCounts      `json:"total"`
+		ByNamespace []clientNamespace `json:"by_namespace"`
+	} `json:"data"`
+}
+
+type activityMonthlyResp struct {
+	Data struct {
+		clientCounts
+		ByNamespace []clientNamespace `json:"by_namespace"`
+	} `json:"data"`
+}
+
+// JSON shape for the client count report
+type jsonClientNamespace struct {
+	Namespace        string         `json:"namespace"`
+	Clients          int            `json:"clients"`
+	EntityClients    int            `json:"entity_clients"`
+	NonEntityClients int            `json:"non_entity_clients"`
+	SecretSyncs      int            `json:"secret_syncs"`
+	ACMEClients      int            `json:"acme_clients"`
+	Mounts           map[string]int `json:"mounts,omitempty"`
+}
+
+type jsonClientReport struct {
+	PeriodStart      string                `json:"period_start,omitempty"`
+	PeriodEnd        string                `json:"period_end,omitempty"`
+	Clients          int                   `json:"clients"`
+	EntityClients    int                   `json:"entity_clients"`
+	NonEntityClients int                   `json:"non_entity_clients"`
+	SecretSyncs      int                   `json:"secret_syncs"`
+	ACMEClients      int                   `json:"acme_clients"`
+	MonthClients     *int                  `json:"month_clients,omitempty"`
+	LicensedClients  int                   `json:"licensed_clients,omitempty"`
+	UtilisationPct   *float64              `json:"utilisation_pct,omitempty"`
+	Namespaces       []jsonClientNamespace `json:"namespaces,omitempty"`
+}
+
+func nsLabel(path string) string {
+	if strings.Trim(path, "/") == "" {
+		return "[root]"
+	}
+	return path
+}
+
+func clientCountDiagnostics(client *http.Client, cfg Config, opt Options) []check {
+	diagnostics := []check{}
+
+	var ar activityResp
+	code, err := doGET(client, cfg, "/v1/sys/internal/counters/activity", &ar)
+	if err != nil || (code != 200 && code != 204) {
+		switch code {
+		case 403:
+			diagnostics = append(diagnostics, check{"clients.activity", "Client count", true, "forbidden (insufficient perms)", nil})
+		case 404:
+			diagnostics = append(diagnostics, check{"clients.activity", "Client count", true, "not available (activity log disabled?)", nil})
+		}
+		return diagnostics
+	}
+
+	t := ar.Data.Total
+	rep := &jsonClientReport{
+		PeriodStart:      ar.Data.StartTime,
+		PeriodEnd:        ar.Data.EndTime,
+		Clients:          t.total(),
+		EntityClients:    t.entities(),
+		NonEntityClients: t.nonEntity(),
+		SecretSyncs:      t.SecretSyncs,
+		ACMEClients:      t.ACMEClients,
+	}
+	period := ""
+	if ar.Data.StartTime != "" {
+		period = fmt.Sprintf(" [%s .. %s]", ar.Data.StartTime, ar.Data.EndTime)
+	}
+	diagnostics = append(diagnostics, check{"clients.billing_period", "Client count (billing)", true, t.String() + period,
+		map[string]any{"clients": rep.Clients, "entity_clients": rep.EntityClients, "non_entity_clients": rep.NonEntityClients}})
+
+	var mr activityMonthlyResp
+	if code, err := doGET(client, cfg, "/v1/sys/internal/counters/activity/monthly", &mr); err == nil && code == 200 {
+		n := mr.Data.total()
+		rep.MonthClients = &n
+		diagnostics = append(diagnostics, check{"clients.current_month", "Client count (month)", true, mr.Data.clientCounts.String(), nil})
+	}
+
+	// without a known limit the counts above are all we can report
+	if limit, source := licensedClients(client, cfg, opt); limit > 0 {
+		pct := float64(rep.Clients) * 100 / float64(limit)
+		rep.LicensedClients = limit
+		rep.UtilisationPct = &pct
+		ok := pct < clientLimitWarnPct
+		diagnostics = append(diagnostics, check{"clients.utilisation", "Client utilisation", ok, fmt.Sprintf("%d/%d (%.1f%%, limit from %s)", rep.Clients, limit, pct, source),
+			map[string]any{"clients": rep.Clients, "licensed": limit, "pct": pct, "limit_source": source}})
+		if !ok {
+			extraHints = append(extraHints, fmt.Sprintf("Client usage is at %.0f%% of the licensed %d clients; review with your account team before the next billing period.", pct, limit))
+		}
+	}
+
+	nss := ar.Data.ByNamespace
+	sort.Slice(nss, func(i, j int) bool { return nss[i].Counts.total() > nss[j].Counts.total() })
+	for i, ns := range nss {
+		jns := jsonClientNamespace{
+			Namespace:        nsLabel(ns.NamespacePath),
+			Clients:          ns.Counts.total(),
+			EntityClients:    ns.Counts.entities(),
+			NonEntityClients: ns.Counts.nonEntity(),
+			SecretSyncs:      ns.Counts.SecretSyncs,
+			ACMEClients:      ns.Counts.ACMEClients,
+		}
+		mounts := ns.Mounts
+		sort.Slice(mounts, func(a, b int) bool { return mounts[a].Counts.total() > mounts[b].Counts.total() })
+		top := []string{}
+		for m, mnt := range mounts {
+			if jns.Mounts == nil {
+				jns.Mounts = map[string]int{}
+			}
+			jns.Mounts[mnt.MountPath] = mnt.Counts.total()
+			if m < clientTopMounts {
+				top = append(top, fmt.Sprintf("%s=%d", mnt.MountPath, mnt.Counts.total()))
+			}
+		}
+		rep.Namespaces = append(rep.Namespaces, jns)
+
+		if i >= clientTopNamespaces {
+			continue
+		}
+		detail := ns.Counts.String()
+		if len(top) > 0 {
+			detail += " top: " + strings.Join(top, ", ")
+		}
+		diagnostics = append(diagnostics, itemCheck("clients.namespace", jns.Namespace, "Clients "+jns.Namespace, true, detail))
+	}
+	if len(nss) > clientTopNamespaces {
+		diagnostics = append(diagnostics, check{"clients.namespace_other", "Clients (other)", true, fmt.Sprintf("%d more namespace(s), see JSON output", len(nss)-clientTopNamespaces), nil})
+	}
+
+	jsonClientCounts = rep
+	return diagnostics
+}
@@ -26,9 +26,9 @@ _vault_doctor()
     local cur prev words cword
     _init_completion || return
 
-    local subcmds="medic completion -h --help -V --version"
+    local subcmds="medic completion schema diff bundle probe -h --help -V --version"
     local global_flags="-h --help -V --version"
-    local medic_flags="--json --quiet --no-color"
+    local medic_flags="--format --template --json --quiet --no-color --client-limit --data-path --recursive-namespaces --kv-max-versions --kv-cas-mounts --kv-delete-after-max --suppress-rules --baseline --save-baseline --anonymize --warn --crit --nagios-warn-diagnostics --notify-webhook --notify-preset --notify-on-change --notify-state"
 
     if [[ ${#COMP_WORDS[@]} -le 2 ]]; then
         COMPREPLY=( $(compgen -W "${subcmds}" -- "$cur") )
@@ -42,6 +42,19 @@ _vault_doctor()
         completion)
             COMPREPLY=( $(compgen -W "bash zsh fish" -- "$cur") )
             ;;
+        diff)
+            COMPREPLY=( $(compgen -W "--format --no-color" -f -- "$cur") )
+            ;;
+        bundle)
+            COMPREPLY=( $(compgen -W "-o --output --anonymize --quiet --no-color" -f -- "$cur") )
+            ;;
+        probe)
+            if [[ "$prev" == "--expect" ]]; then
+                COMPREPLY=( $(compgen -W "active standby perf-standby unsealed initialized" -- "$cur") )
+            else
+                COMPREPLY=( $(compgen -W "--expect --listen --timeout --verbose -v" -- "$cur") )
+            fi
+            ;;
         *)
             COMPREPLY=( $(compgen -W "${global_flags}" -- "$cur") )
             ;;
@@ -53,16 +66,25 @@ complete -F _vault_doctor vault_doctor
 const zshCompletion = `#compdef vault_doctor
 
 _arguments -C \
-  '1: :((medic\:Run\ diagnostics completion\:Generate\ shell\ completions -h\:\:Help --help\:\:Help -V\:\:Version --version\:\:Version))' \
+  '1: :((medic\:Run\ diagnostics completion\:Generate\ shell\ completions schema\:Print\ JSON\ report\ schema diff\:Compare\ two\ JSON\ reports bundle\:Collect\ a\ support\ bundle probe\:Check\ the\ node\ state -h\:\:Help --help\:\:Help -V\:\:Version --version\:\:Version))' \
   '*::arg:->args'
 
 case $words[2] in
   medic)
-    _values 'flags' --json --quiet --no-color
+    _values 'flags' --format --template --json --quiet --no-color --client-limit --data-path --recursive-namespaces --kv-max-versions --kv-cas-mounts --kv-delete-after-max --suppress-rules --baseline --save-baseline --anonymize --warn --crit --nagios-warn-diagnostics --notify-webhook --notify-preset --notify-on-change --notify-state
     ;;
   completion)
     _values 'shell' bash zsh fish
     ;;
+  diff)
+    _arguments '--format[Output format]:format:(pretty json markdown)' '--no-color[Disable colors]' '*:report:_files -g "*.json"'
+    ;;
+  probe)
+    _arguments '--expect[Expected node state]:state:(active standby perf-standby unsealed initialized)' '--listen[Serve over HTTP]:address:' '--timeout[Health call timeout]:duration:' '--verbose[Print the outcome]' '-v[Print the outcome]'
+    ;;
+  bundle)
+    _arguments '-o[Output file]:file:_files' '--output[Output file]:file:_files' '--anonymize[Pseudonymise names]' '--quiet[Only report errors]' '--no-color[Disable colors]'
+    ;;
   *)
     _values 'global' -h --help -V --version
     ;;
@@ -72,11 +94,51 @@ esac
 const fishCompletion = `# fish completion for vault_doctor
 complete -c vault_doctor -f -n "__fish_use_subcommand" -a "medic" -d "Run diagnostics"
 complete -c vault_doctor -f -n "__fish_use_subcommand" -a "completion" -d "Generate shell completions"
+complete -c vault_doctor -f -n "__fish_use_subcommand" -a "schema" -d "Print JSON report schema"
+complete -c vault_doctor -f -n "__fish_use_subcommand" -a "diff" -d "Compare two JSON reports"
+complete -c vault_doctor -f -n "__fish_use_subcommand" -a "bundle" -d "Collect a support bundle"
+complete -c vault_doctor -f -n "__fish_use_subcommand" -a "probe" -d "Check the node state"
 
 # medic flags
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l format -r -a "pretty json markdown html junit template nagios" -d "Report format"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l template -r -F -d "text/template file"
 complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l json -d "Output JSON"
 complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l quiet -d "Quiet mode"
 complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l no-color -d "Disable colors"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l client-limit -r -d "Licensed client count"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l data-path -r -d "Vault data directory"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l recursive-namespaces -d "Walk child namespaces"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l kv-max-versions -r -d "Max KV v2 versions"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l kv-cas-mounts -r -d "Mounts requiring CAS"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l kv-delete-after-max -r -d "Max delete_version_after"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l suppress-rules -r -d "Config rule IDs to skip"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l baseline -r -F -d "Compare against baseline file"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l save-baseline -r -F -d "Write baseline file"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l anonymize -d "Pseudonymise hosts, namespaces and mounts"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l warn -r -d "Nagios warning thresholds"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l crit -r -d "Nagios critical thresholds"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l nagios-warn-diagnostics -d "Warning diagnostics raise WARNING"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l notify-webhook -r -d "POST a summary to this webhook"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l notify-preset -r -a "auto generic slack teams" -d "Webhook payload"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l notify-on-change -d "Notify only on status change"
+complete -c vault_doctor -n "__fish_seen_subcommand_from medic" -l notify-state -r -F -d "Last-status file"
+
+# diff flags
+complete -c vault_doctor -n "__fish_seen_subcommand_from diff" -l format -r -a "pretty json markdown" -d "Output format"
+complete -c vault_doctor -n "__fish_seen_subcommand_from diff" -l no-color -d "Disable colors"
+complete -c vault_doctor -n "__fish_seen_subcommand_from diff" -F
+
+# bundle flags
+complete -c vault_doctor -n "__fish_seen_subcommand_from bundle" -s o -l output -r -F -d "Output file"
+complete -c vault_doctor -n "__fish_seen_subcommand_from bundle" -l anonymize -d "Pseudonymise hosts, namespaces and mounts"
+complete -c vault_doctor -n "__fish_seen_subcommand_from bundle" -l quiet -d "Only report errors"
+complete -c vault_doctor -n "__fish_seen_subcommand_from bundle" -l no-color -d "Disable colors"
+
+# probe flags
+complete -c vault_doctor -n "__fish_seen_subcommand_from probe" -l expect -r -a "active standby perf-standby unsealed initialized" -d "Expected node state"
+complete -c vault_doctor -n "__fish_seen_subcommand_from probe" -l listen -r -d "Serve over HTTP on this address"
+complete -c vault_doctor -n "__fish_seen_subcommand_from probe" -l timeout -r -d "Health call timeout"
+complete -c vault_doctor -n "__fish_seen_subcommand_from probe" -s v -l verbose -d "Print the outcome"
 
 # completion args
 complete -c vault_doctor -n "__fish_seen_subcommand_from completion" -a "bash zsh fish"
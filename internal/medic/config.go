@@ -49,7 +49,7 @@ func NewHTTPClient(skipVerify bool) *http.Client {
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: skipVerify},
 	}
-	return &http.Client{Transport: tr, Timeout: 10 * time.Second}
+	return &http.Client{Transport: tracingTransport{base: tr}, Timeout: 10 * time.Second}
 }
 
 func NewRequestJSON(method, url string, body []byte) (*http.Request, error) {
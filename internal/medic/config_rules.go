@@ -0,0 +1,293 @@
+package medic
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// max_request_size bounds (bytes); Vault's default is 32MiB, -1 disables the limit
+const (
+	maxRequestSizeLow  = 1 << 20
+	maxRequestSizeHigh = 128 << 20
+)
+
+type sanitizedConfigResp struct {
+	Data struct {
+		APIAddr            string `json:"api_addr"`
+		ClusterAddr        string `json:"cluster_addr"`
+		DisableMlock       bool   `json:"disable_mlock"`
+		DisableClustering  bool   `json:"disable_clustering"`
+		RawStorageEndpoint bool   `json:"raw_storage_endpoint"`
+		UI                 bool   `json:"enable_ui"`
+		Listeners          []struct {
+			Type   string         `json:"type"`
+			Config map[string]any `json:"config"`
+		} `json:"listeners"`
+		Storage *struct {
+			Type              string `json:"type"`
+			ClusterAddr       string `json:"cluster_addr"`
+			RedirectAddr      string `json:"redirect_addr"`
+			DisableClustering bool   `json:"disable_clustering"`
+		} `json:"storage"`
+		HAStorage *struct {
+			Type string `json:"type"`
+		} `json:"ha_storage"`
+		Telemetry map[string]any `json:"telemetry"`
+	} `json:"data"`
+}
+
+// A best-practice rule over the sanitized server config. Check returns one
+// finding per offending item (empty when the rule passes).
+type configRule struct {
+	id          string
+	title       string
+	remediation string
+	check       func(c *sanitizedConfigResp) []string
+}
+
+// Telemetry sinks; any of them set means telemetry is configured.
+var telemetrySinkKeys = []string{
+	"statsite_address", "statsd_address", "dogstatsd_addr",
+	"circonus_api_token", "circonus_submission_url",
+	"stackdriver_project_id", "prometheus_retention_time",
+}
+
+func truthy(v any) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		b, _ := strconv.ParseBool(strings.TrimSpace(t))
+		return b
+	case float64:
+		return t != 0
+	}
+	return false
+}
+
+func nonZero(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case string:
+		s := strings.TrimSpace(t)
+		return s != "" && s != "0" && s != "0s"
+	case float64:
+		return t != 0
+	case bool:
+		return t
+	}
+	return true
+}
+
+func asInt64(v any) (int64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return int64(t), true
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(t), 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func listenerAddr(cfg map[string]any) string {
+	if a, ok := cfg["address"].(string); ok && a != "" {
+		return a
+	}
+	return "127.0.0.1:8200" // Vault's default
+}
+
+// publicListener reports whether a listener binds beyond loopback.
+func publicListener(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return !strings.EqualFold(host, "localhost")
+	}
+	return !ip.IsLoopback()
+}
+
+func storageType(c *sanitizedConfigResp) string {
+	if c.Data.Storage == nil {
+		return ""
+	}
+	return strings.ToLower(c.Data.Storage.Type)
+}
+
+var configRules = []configRule{
+	{
+		id:          "VD-CFG-001",
+		title:       "mlock disabled on non-raft storage",
+		remediation: "Remove disable_mlock (or set it false) unless you run integrated storage, where disabling mlock is recommended.",
+		check: func(c *sanitizedConfigResp) []string {
+			if c.Data.DisableMlock && storageType(c) != "raft" && storageType(c) != "" {
+				return []string{fmt.Sprintf("disable_mlock=true with %s storage", storageType(c))}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "VD-CFG-002",
+		title:       "listener without TLS",
+		remediation: "Configure tls_cert_file/tls_key_file on every listener and drop tls_disable; terminate TLS at Vault, not only at the load balancer.",
+		check: func(c *sanitizedConfigResp) []string {
+			out := []string{}
+			for _, l := range c.Data.Listeners {
+				if truthy(l.Config["tls_disable"]) {
+					out = append(out, fmt.Sprintf("%s listener %s has tls_disable", l.Type, listenerAddr(l.Config)))
+				}
+			}
+			return out
+		},
+	},
+	{
+		id:          "VD-CFG-003",
+		title:       "raw storage endpoint enabled",
+		remediation: "Set raw_storage_endpoint=false; sys/raw bypasses the barrier's access controls and should only be enabled for break-glass recovery.",
+		check: func(c *sanitizedConfigResp) []string {
+			if c.Data.RawStorageEndpoint {
+				return []string{"raw_storage_endpoint=true"}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "VD-CFG-004",
+		title:       "api_addr not set",
+		remediation: "Set api_addr to the address clients use to reach this node, so standbys redirect and forward correctly.",
+		check: func(c *sanitizedConfigResp) []string {
+			if strings.TrimSpace(c.Data.APIAddr) == "" {
+				return []string{"api_addr is empty"}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "VD-CFG-005",
+		title:       "cluster_addr not set",
+		remediation: "Set cluster_addr (usually https://<node>:8201) so HA request forwarding and raft replication use a stable address.",
+		check: func(c *sanitizedConfigResp) []string {
+			if c.Data.DisableClustering || (c.Data.Storage != nil && c.Data.Storage.DisableClustering) {
+				return nil
+			}
+			if strings.TrimSpace(c.Data.ClusterAddr) == "" && (c.Data.Storage == nil || strings.TrimSpace(c.Data.Storage.ClusterAddr) == "") {
+				return []string{"cluster_addr is empty"}
+			}
+			return nil
+		},
+	},
+	{
+		id:          "VD-CFG-006",
+		title:       "UI exposed on a public listener",
+		remediation: "Serve the UI only on an internal listener, or put it behind an authenticating proxy; set ui=false on internet-facing nodes.",
+		check: func(c *sanitizedConfigResp) []string {
+			if !c.Data.UI {
+				return nil
+			}
+			out := []string{}
+			for _, l := range c.Data.Listeners {
+				if l.Type == "tcp" && publicListener(listenerAddr(l.Config)) {
+					out = append(out, fmt.Sprintf("ui=true, listener %s is not loopback-only", listenerAddr(l.Config)))
+				}
+			}
+			return out
+		},
+	},
+	{
+		id:          "VD-CFG-007",
+		title:       "no telemetry configured",
+		remediation: "Add a telemetry stanza (e.g. prometheus_retention_time=\"24h\", disable_hostname=true) so metrics are available for alerting.",
+		check: func(c *sanitizedConfigResp) []string {
+			for _, k := range telemetrySinkKeys {
+				if nonZero(c.Data.Telemetry[k]) {
+					return nil
+				}
+			}
+			return []string{"no telemetry sink configured"}
+		},
+	},
+	{
+		id:          "VD-CFG-008",
+		title:       "max_request_size outside sane bounds",
+		remediation: "Keep max_request_size between 1MiB and 128MiB (default 32MiB); never disable it with -1.",
+		check: func(c *sanitizedConfigResp) []string {
+			out := []string{}
+			for _, l := range c.Data.Listeners {
+				n, ok := asInt64(l.Config["max_request_size"])
+				if !ok || n == 0 {
+					continue
+				}
+				switch {
+				case n < 0:
+					out = append(out, fmt.Sprintf("listener %s: max_request_size=%d (unlimited)", listenerAddr(l.Config), n))
+				case n < maxRequestSizeLow || n > maxRequestSizeHigh:
+					out = append(out, fmt.Sprintf("listener %s: max_request_size=%s", listenerAddr(l.Config), humanBytes(uint64(n))))
+				}
+			}
+			return out
+		},
+	},
+}
+
+func fetchSanitizedConfig(client *http.Client, cfg Config) (*sanitizedConfigResp, int) {
+	var sc sanitizedConfigResp
+	code, err := doGET(client, cfg, "/v1/sys/config/state/sanitized", &sc)
+	if err != nil || code != 200 {
+		return nil, code
+	}
+	return &sc, code
+}
+
+func configRuleDiagnostics(client *http.Client, cfg Config, opt Options) []check {
+	diagnostics := []check{}
+	sc, code := fetchSanitizedConfig(client, cfg)
+	serverConfig = sc
+	if sc == nil {
+		if code == 403 {
+			diagnostics = append(diagnostics, check{"config.rules", "Config rules", true, "forbidden (insufficient perms)", nil})
+		}
+		return diagnostics
+	}
+
+	suppressed := map[string]bool{}
+	for _, id := range opt.SuppressRules {
+		suppressed[strings.ToUpper(strings.TrimSpace(id))] = true
+	}
+
+	findings, skipped := []check{}, []string{}
+	for _, r := range configRules {
+		if suppressed[r.id] {
+			skipped = append(skipped, r.id)
+			continue
+		}
+		found := r.check(sc)
+		for _, f := range found {
+			name := fmt.Sprintf("[%s] %s", r.id, r.title)
+			findings = append(findings, check{"config.rule." + r.id, name, false, f, map[string]any{"rule": r.id}})
+		}
+		if len(found) > 0 {
+			extraHints = append(extraHints, fmt.Sprintf("%s: %s", r.id, r.remediation))
+		}
+	}
+	sort.Strings(skipped)
+
+	detail := fmt.Sprintf("%d rule(s), %d finding(s)", len(configRules)-len(skipped), len(findings))
+	if len(skipped) > 0 {
+		detail += ", suppressed " + strings.Join(skipped, ",")
+	}
+	diagnostics = append(diagnostics, check{"config.rules", "Config rules", len(findings) == 0, detail,
+		map[string]any{"rules": len(configRules) - len(skipped), "findings": len(findings), "suppressed": skipped}})
+	diagnostics = append(diagnostics, findings...)
+	return diagnostics
+}
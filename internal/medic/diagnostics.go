@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 )
 
-func runDiagnostics(client *http.Client, cfg Config, health *healthResp) []check {
+func runDiagnostics(client *http.Client, cfg Config, health *healthResp, opt Options) []check {
 	diagnostics := []check{}
 
 	// 0) Version/latency/HA markers
@@ -16,44 +17,49 @@ func runDiagnostics(client *http.Client, cfg Config, health *healthResp) []check
 			if health.Enterprise {
 				v += " (ent)"
 			}
-			diagnostics = append(diagnostics, check{"Vault version", true, v})
+			diagnostics = append(diagnostics, check{"health.version", "Vault version", true, v, nil})
 		}
 		if health.EchoDurationMS != nil {
-			diagnostics = append(diagnostics, check{"Health latency", true, fmt.Sprintf("%dms", *health.EchoDurationMS)})
+			diagnostics = append(diagnostics, check{"health.latency", "Health latency", true, fmt.Sprintf("%dms", *health.EchoDurationMS),
+				map[string]any{"ms": *health.EchoDurationMS}})
 		}
 		if health.HAConnHealthy != nil && (health.Standby != nil && *health.Standby) {
-			diagnostics = append(diagnostics, check{"HA link healthy", *health.HAConnHealthy, fmt.Sprintf("%v", *health.HAConnHealthy)})
+			diagnostics = append(diagnostics, check{"ha.link_healthy", "HA link healthy", *health.HAConnHealthy, fmt.Sprintf("%v", *health.HAConnHealthy), nil})
 		}
 		if health.RemovedFromCL != nil && *health.RemovedFromCL {
-			diagnostics = append(diagnostics, check{"Removed from cluster", false, "true"})
+			diagnostics = append(diagnostics, check{"ha.removed_from_cluster", "Removed from cluster", false, "true", nil})
 		}
 		if health.ReplicationDR != "" && health.ReplicationDR != "disabled" {
-			diagnostics = append(diagnostics, check{"DR mode", true, health.ReplicationDR})
+			diagnostics = append(diagnostics, check{"replication.dr_mode", "DR mode", true, health.ReplicationDR, nil})
 		}
 		if health.ReplicationPerf != "" && health.ReplicationPerf != "disabled" {
-			diagnostics = append(diagnostics, check{"Performance mode", true, health.ReplicationPerf})
+			diagnostics = append(diagnostics, check{"replication.performance_mode", "Performance mode", true, health.ReplicationPerf, nil})
 		}
 		if health.ReplicationDRLegacy != nil && health.ReplicationDRLegacy.Mode != "" {
-			diagnostics = append(diagnostics, check{"DR mode", true, health.ReplicationDRLegacy.Mode})
+			diagnostics = append(diagnostics, check{"replication.dr_mode", "DR mode", true, health.ReplicationDRLegacy.Mode, nil})
 		}
 		if health.ReplicationPerfLegacy != nil && health.ReplicationPerfLegacy.Mode != "" {
-			diagnostics = append(diagnostics, check{"Performance mode", true, health.ReplicationPerfLegacy.Mode})
+			diagnostics = append(diagnostics, check{"replication.performance_mode", "Performance mode", true, health.ReplicationPerfLegacy.Mode, nil})
 		}
 	}
 
 	// 1) Leader info
+	st := startStep("diagnostics", "Leader info", len(diagnostics))
 	type leaderResp struct {
-		HAEnabled bool   `json:"ha_enabled"`
-		IsSelf    *bool  `json:"is_self,omitempty"`
-		Leader    string `json:"leader_address"`
+		HAEnabled          bool   `json:"ha_enabled"`
+		IsSelf             *bool  `json:"is_self,omitempty"`
+		Leader             string `json:"leader_address"`
+		RaftCommittedIndex uint64 `json:"raft_committed_index"`
 	}
 	var lr leaderResp
 	if code, err := doGET(client, cfg, "/v1/sys/leader", &lr); err == nil && code == 200 {
+		leaderHA = &lr.HAEnabled
+		leaderRaftIndex = lr.RaftCommittedIndex
 		addr := strings.TrimSpace(lr.Leader)
 		if addr == "" {
 			addr = cfg.Addr
 		}
-		diagnostics = append(diagnostics, check{"Leader address", true, addr})
+		diagnostics = append(diagnostics, check{"leader.address", "Leader address", true, addr, nil})
 
 		isSelf := false
 		if lr.IsSelf != nil {
@@ -64,88 +70,35 @@ func runDiagnostics(client *http.Client, cfg Config, health *healthResp) []check
 		} else if sameAddress(lr.Leader, cfg.Addr) || strings.TrimSpace(lr.Leader) == "" {
 			isSelf = true
 		}
-		diagnostics = append(diagnostics, check{"Leader is self", true, fmt.Sprintf("%v", isSelf)})
+		diagnostics = append(diagnostics, check{"leader.is_self", "Leader is self", true, fmt.Sprintf("%v", isSelf), nil})
 	} else if code == 403 {
-		diagnostics = append(diagnostics, check{"Leader info", true, "forbidden (insufficient perms)"})
+		diagnostics = append(diagnostics, check{"leader.info", "Leader info", true, "forbidden (insufficient perms)", nil})
 	}
+	st.done(diagnostics)
 
 	// 2) Seal status
-	type sealStatus struct {
-		Type      string `json:"type"`
-		Threshold int    `json:"t"`
-		N         int    `json:"n"`
-		Progress  int    `json:"progress"`
-	}
-	var ss sealStatus
-	if code, err := doGET(client, cfg, "/v1/sys/seal-status", &ss); err == nil && code == 200 {
-		jsonSealType = ss.Type
-		autoUnseal := ss.Threshold == 0 && ss.N == 0
-		if autoUnseal {
-			jsonSealThresh = ""
-			jsonSealProg = nil
-			diagnostics = append(diagnostics, check{"Seal type", true, ss.Type})
-		} else {
-			jsonSealThresh = fmt.Sprintf("%d/%d", ss.Threshold, ss.N)
-			jsonSealProg = &ss.Progress
-			diagnostics = append(diagnostics, check{"Seal type", true, fmt.Sprintf("%s (threshold %s, progress %d)", ss.Type, jsonSealThresh, ss.Progress)})
-		}
-	}
+	st = startStep("diagnostics", "Seal status", len(diagnostics))
+	diagnostics = append(diagnostics, sealDiagnostics(client, cfg)...)
+	st.done(diagnostics)
 
-	// 3) Secret engines + KV flavors
-	type mounts struct {
-		Data map[string]struct {
-			Type    string         `json:"type"`
-			Options map[string]any `json:"options"`
-		} `json:"data"`
-	}
-	var m mounts
-	if code, err := doGET(client, cfg, "/v1/sys/mounts", &m); err == nil && (code == 200 || code == 204) {
-		total := 0
-		kvTotal := 0
-		kvV2 := 0
-		for path, mount := range m.Data {
-			if path == "" {
-				continue
-			}
-			total++
-			if mount.Type == "kv" || mount.Type == "generic" {
-				kvTotal++
-				if mount.Options != nil {
-					if verRaw, ok := mount.Options["version"]; ok {
-						if fmt.Sprintf("%v", verRaw) == "2" {
-							kvV2++
-						}
-					}
-				}
-			}
-		}
-		diagnostics = append(diagnostics, check{"Secret engines", true, fmt.Sprintf("%d", total)})
-		kvV1 := kvTotal - kvV2
-		diagnostics = append(diagnostics, check{"KV engines", true, fmt.Sprintf("total=%d (v2=%d, v1=%d)", kvTotal, kvV2, kvV1)})
-	} else if code == 403 {
-		diagnostics = append(diagnostics, check{"Secret engines", true, "forbidden (insufficient perms)"})
-	}
+	// 3) Lease inventory + irrevocable leases
+	st = startStep("diagnostics", "Lease inventory", len(diagnostics))
+	diagnostics = append(diagnostics, leaseDiagnostics(client, cfg)...)
+	st.done(diagnostics)
 
-	// 4) Auth methods
-	type auths struct {
-		Data map[string]struct {
-			Type string `json:"type"`
-		} `json:"data"`
-	}
-	var a auths
-	if code, err := doGET(client, cfg, "/v1/sys/auth", &a); err == nil && code == 200 {
-		cnt := 0
-		for p := range a.Data {
-			if p != "" {
-				cnt++
-			}
-		}
-		diagnostics = append(diagnostics, check{"Auth methods", true, fmt.Sprintf("%d", cnt)})
-	} else if code == 403 {
-		diagnostics = append(diagnostics, check{"Auth methods", true, "forbidden (insufficient perms)"})
+	// 4) Namespace-scoped: mounts, auth methods, policies, quotas (uses the lease inventory above)
+	st = startStep("diagnostics", "Namespace diagnostics", len(diagnostics))
+	current := namespaceDiagnostics(client, cfg, leaseCountsByMount, opt, st)
+	diagnostics = append(diagnostics, current.diags...)
+	st.done(diagnostics)
+	if opt.RecursiveNamespaces {
+		st = startStep("diagnostics", "Namespace walk", len(diagnostics))
+		runNamespaceWalk(client, cfg, current, opt)
+		st.done(diagnostics)
 	}
 
 	// 5) Token introspection
+	st = startStep("diagnostics", "Token introspection", len(diagnostics))
 	type tokenSelf struct {
 		Data struct {
 			Policies  []string `json:"policies"`
@@ -156,7 +109,7 @@ func runDiagnostics(client *http.Client, cfg Config, health *healthResp) []check
 	}
 	var ts tokenSelf
 	if code, err := doGET(client, cfg, "/v1/auth/token/lookup-self", &ts); err == nil && code == 200 {
-		diagnostics = append(diagnostics, check{"Token policies", true, strings.Join(ts.Data.Policies, ",")})
+		diagnostics = append(diagnostics, check{"token.policies", "Token policies", true, strings.Join(ts.Data.Policies, ","), nil})
 
 		ttlStr := humanTTL(ts.Data.TTL)
 		if ts.Data.TTL <= 0 {
@@ -166,17 +119,51 @@ func runDiagnostics(client *http.Client, cfg Config, health *healthResp) []check
 		}
 		jsonTokenRen = &ts.Data.Renewable
 		jsonTokenOrph = &ts.Data.Orphan
+		ttlData := map[string]any{"ttl_seconds": ts.Data.TTL, "renewable": ts.Data.Renewable, "orphan": ts.Data.Orphan}
 
 		if ts.Data.TTL <= 0 {
-			diagnostics = append(diagnostics, check{"Token TTL", true,
-				fmt.Sprintf("%s (renewable=%v, orphan=%v) — non-expiring", ttlStr, ts.Data.Renewable, ts.Data.Orphan)})
+			diagnostics = append(diagnostics, check{"token.ttl", "Token TTL", true,
+				fmt.Sprintf("%s (renewable=%v, orphan=%v) — non-expiring", ttlStr, ts.Data.Renewable, ts.Data.Orphan), ttlData})
 		} else {
-			diagnostics = append(diagnostics, check{"Token TTL", true,
-				fmt.Sprintf("%s (renewable=%v, orphan=%v)", ttlStr, ts.Data.Renewable, ts.Data.Orphan)})
+			diagnostics = append(diagnostics, check{"token.ttl", "Token TTL", true,
+				fmt.Sprintf("%s (renewable=%v, orphan=%v)", ttlStr, ts.Data.Renewable, ts.Data.Orphan), ttlData})
 		}
 	} else if code == 403 {
-		diagnostics = append(diagnostics, check{"Token policies", true, "forbidden (insufficient perms)"})
+		diagnostics = append(diagnostics, check{"token.policies", "Token policies", true, "forbidden (insufficient perms)", nil})
 	}
+	st.done(diagnostics)
+
+	// 6) Client count / license utilisation (Enterprise)
+	if health != nil && health.Enterprise {
+		st = startStep("diagnostics", "Client count", len(diagnostics))
+		diagnostics = append(diagnostics, clientCountDiagnostics(client, cfg, opt)...)
+		st.done(diagnostics)
+	}
+
+	// 7) Plugin catalog + mounts on deprecated builtins
+	st = startStep("diagnostics", "Plugin catalog", len(diagnostics))
+	diagnostics = append(diagnostics, pluginDiagnostics(client, cfg)...)
+	st.done(diagnostics)
+
+	// 8) Host resources
+	st = startStep("diagnostics", "Host resources", len(diagnostics))
+	diagnostics = append(diagnostics, hostDiagnostics(client, cfg, opt)...)
+	st.done(diagnostics)
+
+	// 9) Server config best-practice rules (sanitized config state)
+	st = startStep("diagnostics", "Config rules", len(diagnostics))
+	diagnostics = append(diagnostics, configRuleDiagnostics(client, cfg, opt)...)
+	st.done(diagnostics)
+
+	// 10) Storage backend + HA (uses sanitized config and leader info above)
+	st = startStep("diagnostics", "Storage backend", len(diagnostics))
+	diagnostics = append(diagnostics, storageDiagnostics(client, cfg, health)...)
+	st.done(diagnostics)
+
+	// 11) Telemetry snapshot (printed as its own section)
+	st = startStep("telemetry", "Telemetry", 0)
+	telemetryChecks = telemetryDiagnostics(client, cfg)
+	st.done(telemetryChecks)
 
 	return diagnostics
 }
@@ -190,4 +177,37 @@ var (
 	jsonTokenTTL  string
 	jsonTokenRen  *bool
 	jsonTokenOrph *bool
+
+	jsonClientCounts *jsonClientReport
+	jsonHost         *jsonHostInfo
+
+	// sanitized server config, nil when not readable
+	serverConfig *sanitizedConfigResp
+
+	// from /sys/leader, used for storage/HA detection
+	leaderHA        *bool
+	leaderRaftIndex uint64
+
+	// storage_type reported by /sys/seal-status
+	sealStorageType string
+
+	jsonStorage   string
+	jsonHAEnabled *bool
+	jsonNodeCount *int
+
+	// curated sys/metrics indicators, shown in the "Telemetry" section
+	telemetryChecks []check
+
+	// per-namespace reports, filled when --recursive-namespaces is set
+	nsReports []namespaceReport
+
+	// leases per mount prefix, filled by leaseDiagnostics
+	leaseCountsByMount map[string]int
+	leaseCountsPartial bool // lease walk ran out of budget
+
+	// hints raised by diagnostics, merged into "Next actions"
+	extraHints []string
+
+	// set when Run starts, for the report duration
+	runStarted time.Time
 )
@@ -0,0 +1,417 @@
+package medic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats accepted by "vault_doctor diff --format".
+var DiffFormats = []string{"pretty", "json", "markdown"}
+
+type diffSide struct {
+	File      string `json:"file"`
+	Timestamp int64  `json:"timestamp"`
+	Version   string `json:"version,omitempty"`
+}
+
+type fieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type countChange struct {
+	Name   string `json:"name"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+	Delta  int    `json:"delta"`
+}
+
+type checkChange struct {
+	Section   string `json:"section"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Before    string `json:"before"`
+	After     string `json:"after"`
+	Detail    string `json:"detail,omitempty"`
+	Regressed bool   `json:"regressed"`
+}
+
+type checkRef struct {
+	Section  string `json:"section"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Severity string `json:"severity"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type reportDiff struct {
+	Before        diffSide      `json:"before"`
+	After         diffSide      `json:"after"`
+	Metadata      []fieldChange `json:"metadata,omitempty"`
+	Counts        []countChange `json:"counts,omitempty"`
+	StatusChanges []checkChange `json:"status_changes,omitempty"`
+	Added         []checkRef    `json:"added,omitempty"`
+	Removed       []checkRef    `json:"removed,omitempty"`
+	Regressions   int           `json:"regressions"`
+}
+
+func (d reportDiff) changes() int {
+	return len(d.Metadata) + len(d.Counts) + len(d.StatusChanges) + len(d.Added) + len(d.Removed)
+}
+
+// Counts compared between runs: check ID, data key, display name.
+var diffCounts = []struct{ id, key, name string }{
+	{"mount.secret_engines", "count", "Secret engines"},
+	{"mount.kv_engines", "total", "KV engines"},
+	{"auth.methods", "count", "Auth methods"},
+	{"policy.acl_count", "count", "ACL policies"},
+	{"plugin.catalog", "external", "External plugins"},
+	{"lease.inventory", "total", "Leases"},
+	{"lease.irrevocable_count", "count", "Irrevocable leases"},
+	{"raft.peers", "nodes", "Raft nodes"},
+}
+
+var sevRank = map[string]int{sevOK: 0, sevSkipped: 0, sevWarning: 1, sevCritical: 2}
+
+func loadReport(path string) (*jsonResult, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r jsonResult
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("%s: not a medic JSON report: %w", path, err)
+	}
+	if r.Timestamp == 0 && len(r.Checks) == 0 {
+		return nil, fmt.Errorf("%s: not a medic JSON report", path)
+	}
+	// reports written before schema 1.0 carry neither IDs nor severities;
+	// their checks are matched by display name (see checkKeys)
+	fill := func(cs []jsonCheck, failSev string) {
+		for i := range cs {
+			if cs[i].Severity == "" {
+				cs[i].Severity = severity(check{name: cs[i].Name, ok: cs[i].OK, detail: cs[i].Detail}, failSev)
+			}
+		}
+	}
+	fill(r.Checks, sevCritical)
+	fill(r.Diagnostics, sevWarning)
+	fill(r.Telemetry, sevWarning)
+	return &r, nil
+}
+
+// checkKey identifies one check: its ID plus the per-item subject (mount
+// path, role, ...), or its name for display-only rows.
+func checkKey(id, name string, data map[string]any) string {
+	if id == "" {
+		return name
+	}
+	if key, ok := checkSubjects[id]; ok {
+		return id + ":" + fmt.Sprint(data[key])
+	}
+	return id
+}
+
+// checkKeys identifies each check across runs by checkKey, numbered when a
+// run repeats it.
+func checkKeys(cs []jsonCheck) []string {
+	keys := make([]string, 0, len(cs))
+	seen := map[string]int{}
+	for _, c := range cs {
+		k := checkKey(c.ID, c.Name, c.Data)
+		seen[k]++
+		if seen[k] > 1 {
+			k = fmt.Sprintf("%s#%d", k, seen[k])
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func diffKeys(cs []jsonCheck) ([]string, map[string]jsonCheck) {
+	keys := checkKeys(cs)
+	byKey := map[string]jsonCheck{}
+	for i, k := range keys {
+		byKey[k] = cs[i]
+	}
+	return keys, byKey
+}
+
+// reportCount reads a count from a check's data, falling back to a plain
+// integer detail for older reports.
+func reportCount(r *jsonResult, id, key string) (int, bool) {
+	for _, c := range r.Diagnostics {
+		if c.ID != id {
+			continue
+		}
+		if v, ok := c.Data[key].(float64); ok {
+			return int(v), true
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(c.Detail)); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func checkDetail(r *jsonResult, id string) string {
+	for _, c := range r.Checks {
+		if c.ID == id {
+			return c.Detail
+		}
+	}
+	return ""
+}
+
+func optStr(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
+func optInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func diffReports(a, b *jsonResult) reportDiff {
+	d := reportDiff{}
+
+	field := func(name, before, after string) {
+		if before != after {
+			d.Metadata = append(d.Metadata, fieldChange{name, before, after})
+		}
+	}
+	vaultVersion := func(r *jsonResult) string {
+		return strings.TrimSuffix(checkDetail(r, "health.version"), " (enterprise detected)")
+	}
+	field("Vault version", vaultVersion(a), vaultVersion(b))
+	field("Mode", a.Mode, b.Mode)
+	field("Cluster name", a.ClusterName, b.ClusterName)
+	field("Leader address", a.LeaderAddress, b.LeaderAddress)
+	field("Leader is self", optStr(a.LeaderIsSelf), optStr(b.LeaderIsSelf))
+	field("Seal type", a.SealType, b.SealType)
+	field("Storage type", a.StorageType, b.StorageType)
+	field("HA enabled", optStr(a.HAEnabled), optStr(b.HAEnabled))
+	field("Node count", optInt(a.NodeCount), optInt(b.NodeCount))
+	field("Failures", strconv.Itoa(a.Failures), strconv.Itoa(b.Failures))
+
+	for _, c := range diffCounts {
+		before, okA := reportCount(a, c.id, c.key)
+		after, okB := reportCount(b, c.id, c.key)
+		if (okA || okB) && before != after {
+			d.Counts = append(d.Counts, countChange{c.name, before, after, after - before})
+		}
+	}
+	if a.NamespaceTotals != nil && b.NamespaceTotals != nil && *a.NamespaceTotals != *b.NamespaceTotals {
+		ta, tb := *a.NamespaceTotals, *b.NamespaceTotals
+		for _, c := range []countChange{
+			{"Namespace mounts (total)", ta.Mounts, tb.Mounts, 0},
+			{"Namespace auth methods (total)", ta.AuthMethods, tb.AuthMethods, 0},
+			{"Namespace policies (total)", ta.Policies, tb.Policies, 0},
+			{"Namespace quotas (total)", ta.Quotas, tb.Quotas, 0},
+		} {
+			if c.Before != c.After {
+				c.Delta = c.After - c.Before
+				d.Counts = append(d.Counts, c)
+			}
+		}
+	}
+
+	section := func(name string, before, after []jsonCheck) {
+		keysA, byA := diffKeys(before)
+		keysB, byB := diffKeys(after)
+		for _, k := range keysB {
+			cb := byB[k]
+			ca, ok := byA[k]
+			if !ok {
+				d.Added = append(d.Added, checkRef{name, cb.ID, cb.Name, cb.Severity, cb.Detail})
+				if sevRank[cb.Severity] > 0 {
+					d.Regressions++
+				}
+				continue
+			}
+			if ca.Severity != cb.Severity {
+				worse := sevRank[cb.Severity] > sevRank[ca.Severity]
+				d.StatusChanges = append(d.StatusChanges, checkChange{name, cb.ID, cb.Name, ca.Severity, cb.Severity, cb.Detail, worse})
+				if worse {
+					d.Regressions++
+				}
+			}
+		}
+		for _, k := range keysA {
+			if _, ok := byB[k]; !ok {
+				ca := byA[k]
+				d.Removed = append(d.Removed, checkRef{name, ca.ID, ca.Name, ca.Severity, ca.Detail})
+			}
+		}
+	}
+	section("checks", a.Checks, b.Checks)
+	section("diagnostics", a.Diagnostics, b.Diagnostics)
+	section("telemetry", a.Telemetry, b.Telemetry)
+
+	// regressions first
+	sort.SliceStable(d.StatusChanges, func(i, j int) bool {
+		return d.StatusChanges[i].Regressed && !d.StatusChanges[j].Regressed
+	})
+	return d
+}
+
+// Diff compares two saved medic JSON reports. Exit code: 0 no regressions,
+// 1 a check got worse or a new failing check appeared, 2 bad input.
+func Diff(beforePath, afterPath string, opt Options) int {
+	a, err := loadReport(beforePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "diff:", err)
+		return 2
+	}
+	b, err := loadReport(afterPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "diff:", err)
+		return 2
+	}
+	d := diffReports(a, b)
+	d.Before = diffSide{beforePath, a.Timestamp, a.Version}
+	d.After = diffSide{afterPath, b.Timestamp, b.Version}
+
+	switch opt.format() {
+	case "json":
+		_ = mustJSONEncoder().Encode(d)
+	case "markdown":
+		renderDiffMarkdown(os.Stdout, d)
+	default:
+		printDiff(d, opt)
+	}
+	if d.Regressions > 0 {
+		return 1
+	}
+	return 0
+}
+
+func diffStamp(s diffSide) string {
+	return fmt.Sprintf("%s (%s)", s.File, time.Unix(s.Timestamp, 0).UTC().Format(time.RFC3339))
+}
+
+func diffSummary(d reportDiff) string {
+	if d.changes() == 0 {
+		return "No differences"
+	}
+	return fmt.Sprintf("%d change(s), %d regression(s)", d.changes(), d.Regressions)
+}
+
+func printDiff(d reportDiff, opt Options) {
+	fmt.Printf("%s %s\n", cwrap("🩺 vault_doctor", colGreen, opt), cwrap("diff", colYellow, opt))
+	fmt.Printf("  before: %s\n  after:  %s\n", diffStamp(d.Before), diffStamp(d.After))
+
+	rows := []check{}
+	for _, m := range d.Metadata {
+		rows = append(rows, check{name: m.Field, ok: true, detail: fmt.Sprintf("%s → %s", orNone(m.Before), orNone(m.After))})
+	}
+	printSection("Cluster", rows, opt)
+
+	rows = []check{}
+	for _, c := range d.Counts {
+		rows = append(rows, check{name: c.Name, ok: true, detail: fmt.Sprintf("%d → %d (%+d)", c.Before, c.After, c.Delta)})
+	}
+	printSection("Counts", rows, opt)
+
+	rows = []check{}
+	for _, c := range d.StatusChanges {
+		detail := fmt.Sprintf("%s → %s", c.Before, c.After)
+		if c.Detail != "" {
+			detail += "  " + c.Detail
+		}
+		rows = append(rows, check{name: c.Name, ok: !c.Regressed, detail: detail})
+	}
+	printSection("Status changes", rows, opt)
+
+	rows = []check{}
+	for _, c := range d.Added {
+		rows = append(rows, check{name: "+ " + c.Name, ok: sevRank[c.Severity] == 0, detail: fmt.Sprintf("%s  %s", c.Severity, c.Detail)})
+	}
+	for _, c := range d.Removed {
+		rows = append(rows, check{name: "- " + c.Name, ok: true, detail: fmt.Sprintf("%s  %s", c.Severity, c.Detail)})
+	}
+	printSection("Added / removed", rows, opt)
+
+	fmt.Println()
+	if d.Regressions > 0 {
+		fmt.Println(cwrap(diffSummary(d), colRed, opt))
+	} else {
+		fmt.Println(cwrap(diffSummary(d), colGreen, opt))
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func renderDiffMarkdown(w io.Writer, d reportDiff) {
+	fmt.Fprintln(w, "# vault_doctor diff")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "- **Before:** %s\n", mdCell(diffStamp(d.Before)))
+	fmt.Fprintf(w, "- **After:** %s\n", mdCell(diffStamp(d.After)))
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "**%s**\n", diffSummary(d))
+
+	if len(d.Metadata) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "## Cluster")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| Field | Before | After |")
+		fmt.Fprintln(w, "|---|---|---|")
+		for _, m := range d.Metadata {
+			fmt.Fprintf(w, "| %s | %s | %s |\n", mdCell(m.Field), mdCell(orNone(m.Before)), mdCell(orNone(m.After)))
+		}
+	}
+	if len(d.Counts) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "## Counts")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| Count | Before | After | Delta |")
+		fmt.Fprintln(w, "|---|---|---|---|")
+		for _, c := range d.Counts {
+			fmt.Fprintf(w, "| %s | %d | %d | %+d |\n", mdCell(c.Name), c.Before, c.After, c.Delta)
+		}
+	}
+	if len(d.StatusChanges) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "## Status changes")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| | Section | Check | Before | After | Detail |")
+		fmt.Fprintln(w, "|---|---|---|---|---|---|")
+		for _, c := range d.StatusChanges {
+			mark := "improved"
+			if c.Regressed {
+				mark = "**regressed**"
+			}
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s |\n", mark, c.Section, mdCell(c.Name), c.Before, c.After, mdCell(c.Detail))
+		}
+	}
+	if len(d.Added)+len(d.Removed) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "## Added / removed")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| | Section | Check | Severity | Detail |")
+		fmt.Fprintln(w, "|---|---|---|---|---|")
+		for _, c := range d.Added {
+			fmt.Fprintf(w, "| added | %s | %s | %s | %s |\n", c.Section, mdCell(c.Name), c.Severity, mdCell(c.Detail))
+		}
+		for _, c := range d.Removed {
+			fmt.Fprintf(w, "| removed | %s | %s | %s | %s |\n", c.Section, mdCell(c.Name), c.Severity, mdCell(c.Detail))
+		}
+	}
+}
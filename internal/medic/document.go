@@ -8,7 +8,19 @@ func Doc(version string) string {
 
 Usage:
   vault_doctor completion [bash|zsh|fish]
-  vault_doctor medic [--json] [--quiet] [--no-color]
+  vault_doctor schema
+  vault_doctor diff [--format pretty|json|markdown] [--no-color]
+                    before.json after.json
+  vault_doctor bundle [-o FILE] [--anonymize] [--quiet] [--no-color]
+  vault_doctor probe [--expect STATE] [--listen ADDR] [--timeout DUR] [-v]
+  vault_doctor medic [--format FMT] [--template FILE] [--json] [--quiet] [--no-color] [--client-limit N]
+                     [--data-path DIR] [--recursive-namespaces] [--kv-max-versions N]
+                     [--kv-cas-mounts GLOBS] [--kv-delete-after-max DUR]
+                     [--suppress-rules IDS] [--baseline FILE]
+                     [--save-baseline FILE] [--anonymize]
+                     [--warn SPECS] [--crit SPECS] [--nagios-warn-diagnostics]
+                     [--notify-webhook URL] [--notify-preset P]
+                     [--notify-on-change] [--notify-state FILE]
   vault_doctor -V|--version
   vault_doctor -h|--help
 
@@ -16,9 +28,145 @@ Version:
   %s
 
 Flags (medic):
+  --format FMT Report format: pretty (default), json, markdown, html, junit,
+               template or nagios. markdown and html are shareable documents
+               for tickets and change records; html is one file with inline
+               CSS. junit emits JUnit XML for CI: testcases are named by
+               check ID, failing results are failures, warnings pass with a
+               severity property, forbidden/skipped checks are skipped.
+               nagios is a monitoring plugin, see "Nagios".
+  --template FILE
+               Render the report through a Go text/template (implies
+               --format template). The data is the JSON report (.Checks,
+               .Diagnostics, .Hints, .Failures, .ClusterName, ...); funcs:
+               colour, cwrap, pad, mark, humanTTL, nameColWidth, summary,
+               join, upper, lower, json. Example line per failed check:
+                 {{range .Checks}}{{if not .OK}}{{.Name}},{{.Detail}}
+                 {{end}}{{end}}
   --json       Output machine-readable JSON (no banner, no prompts).
+               Alias for --format json.
   --quiet      Suppress pretty output and prompts (exit code reflects status).
   --no-color   Disable ANSI colors (NO_COLOR=1 also works).
+  --client-limit N
+               Licensed client count; Enterprise client usage is reported
+               as a percentage of it (flagged at 80%%). Defaults to the
+               limit in sys/license/status when the license reports one;
+               without either, only the counts are shown.
+  --data-path DIR
+               Vault data directory (e.g. /opt/vault/data). Every disk from
+               sys/host-info is reported; the one holding DIR is marked and
+               its hint names the data directory. Vault does not expose
+               storage.path, so this is not detected automatically.
+  --recursive-namespaces
+               Walk sys/namespaces from VAULT_NAMESPACE downward and run the
+               mount, auth, policy and quota diagnostics in each namespace.
+  --kv-max-versions N
+               Flag KV v2 mounts whose max_versions exceeds N.
+  --kv-cas-mounts GLOBS
+               Comma-separated mount globs (e.g. "prod-*") that must have
+               cas_required=true.
+  --kv-delete-after-max DUR
+               Flag KV v2 mounts whose delete_version_after is unset or
+               longer than DUR (e.g. 2160h).
+  --suppress-rules IDS
+               Comma-separated server config rule IDs to skip
+               (VD-CFG-001 .. VD-CFG-008, see "Config rules" below).
+  --save-baseline FILE
+               Write the cluster's shape (mount and auth paths/types, audit
+               devices, policy names, seal type, replication mode, raft node
+               count) to FILE.
+  --baseline FILE
+               Compare against a saved baseline; any drift (e.g. a new auth
+               method or a disabled audit device) is a failed check.
+  --anonymize  Replace hostnames, IPs, the cluster name, namespace names and
+               mount paths with pseudonyms (host-1, ns-1/, mount-1/, ...)
+               that stay consistent within the report, so it can be shared.
+               Generic names (vault, prod, dev, ...) are kept as they are.
+               Tokens, accessors, SecretIDs and unseal keys are redacted in
+               every output mode, with or without this flag.
+  --notify-webhook URL
+               After the run, POST a summary (status, failed and warning
+               checks, next actions) to URL. Delivery errors are printed to
+               stderr and do not change the exit code.
+  --notify-preset P
+               Payload shape: generic (JSON), slack (blocks), teams
+               (MessageCard) or auto (default: slack/teams by webhook host,
+               else generic).
+  --notify-on-change
+               Only notify when the status (ok|warning|critical) or the set
+               of failed or warning checks changed since the last run.
+               Pair with cron for lightweight alerting.
+  --notify-state FILE
+               Where the last status is remembered (default: one file per
+               VAULT_ADDR under the user cache dir, e.g.
+               ~/.cache/vault_doctor/).
+
+JSON report:
+  "vault_doctor schema" prints the JSON Schema of --format json. Every check
+  carries a stable id (e.g. seal.type, mount.entry), a severity
+  (ok|warning|critical|skipped) and, where useful, structured data such as
+  integer counts. schema_version is bumped on any shape change: minor for
+  added fields, major for removals. Detail strings are for humans and may
+  be reworded; match on id and data instead. "timings" lists every step
+  with its duration and HTTP calls (dns, connect, tls, time to first
+  byte). Each check names its step and carries duration_ms: the time of
+  its sub-diagnostic where a step is split (mounts, auth methods, policies
+  and quotas within the namespace diagnostics), else of the whole step.
+  Pretty output ends with the slowest steps.
+
+Diff:
+  "vault_doctor diff" compares two saved --format json reports: cluster
+  metadata (mode, leader, version, seal, storage), counts (mounts, auth
+  methods, policies, leases, nodes), checks that changed severity, and
+  checks that appeared or disappeared. Exit code 1 when anything regressed
+  (a check got worse or a new failing check appeared), 2 on bad input.
+
+Nagios:
+  --format nagios prints one plugin status line with perfdata, then one
+  line per failed check:
+    VAULT OK - active, unsealed | latency=12ms;250;1000;0 token_ttl=...
+  The status line names the first 3 problems and counts the rest
+  ("5 problem(s): a; b; c (+2 more)"). Exit codes: 0 OK, 1 WARNING,
+  2 CRITICAL, 3 UNKNOWN. Failed checks are CRITICAL; missing VAULT_ADDR,
+  auth config or a failed AppRole login is UNKNOWN. Only the metric
+  thresholds below raise WARNING; warning diagnostics and telemetry are
+  counted (warnings=N) and, with --nagios-warn-diagnostics, also raise
+  WARNING and get a line each. Thresholds use the plugin range syntax
+  ("250" alerts above 250, "3600:" below 3600, "@10:20" inside) per metric:
+    --warn latency=250,token_ttl=86400:   (defaults)
+    --crit latency=1000,token_ttl=3600:   (defaults)
+  Metrics: latency (ms), token_ttl (s, omitted for non-expiring tokens),
+  seal_progress (unseal keys entered, no default). "METRIC=" clears one.
+
+Probe:
+  "vault_doctor probe --expect STATE" makes one sys/health call (no token
+  needed), prints nothing and exits 0 if the node is in STATE, 1 if not or
+  unreachable, 2 on bad usage. STATE is active, standby (includes perf
+  standbys), perf-standby, unsealed (default) or initialized. -v prints
+  the outcome. With --listen ADDR (e.g. :8080) it serves the same check
+  for Kubernetes probes and load balancers: "/", /healthz, /readyz and
+  /livez check --expect, /active, /standby, ... check that state; 200 on
+  match, 503 otherwise.
+
+Bundle:
+  "vault_doctor bundle -o vault-support.tar.gz" collects, for an incident or
+  support case, the medic JSON report plus one file per endpoint: health,
+  seal status, leader, sanitized config state, host-info, replication and
+  raft status, metrics, plugin catalog, and mount/auth/audit listings.
+  manifest.json records which endpoints succeeded, were forbidden or
+  failed. Everything is redacted as in medic; --anonymize pseudonymises
+  names consistently across all files, including the hostname Vault puts
+  in metric names. Exit code 2 if the bundle could not be written.
+
+Config rules (from sys/config/state/sanitized):
+  VD-CFG-001  disable_mlock on non-raft storage
+  VD-CFG-002  listener with tls_disable
+  VD-CFG-003  raw_storage_endpoint enabled
+  VD-CFG-004  api_addr not set
+  VD-CFG-005  cluster_addr not set
+  VD-CFG-006  ui enabled on a non-loopback listener
+  VD-CFG-007  no telemetry sink configured
+  VD-CFG-008  max_request_size unlimited, <1MiB or >128MiB
 
 Environment variables (read directly and via .env if present):
   VAULT_ADDR         https://<host>:8200
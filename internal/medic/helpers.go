@@ -3,6 +3,7 @@ package medic
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
@@ -69,6 +70,14 @@ func sameAddress(a, b string) bool {
 	return ax != "" && bx != "" && ax == bx
 }
 
+// msSince is the elapsed time in milliseconds, rounded to 0.1ms (0 if t is unset).
+func msSince(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return ms(time.Since(t))
+}
+
 func humanTTL(sec int64) string {
 	if sec <= 0 {
 		return "∞"
@@ -105,6 +114,58 @@ func doGET(client *http.Client, cfg Config, path string, out any) (int, error) {
 	return code, nil
 }
 
+// Raw GET helper for non-JSON bodies (e.g. Prometheus text)
+func doGETRaw(client *http.Client, cfg Config, path string) (int, []byte, error) {
+	url := strings.TrimRight(cfg.Addr, "/") + path
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	withVaultHeaders(req, cfg)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	return res.StatusCode, body, err
+}
+
+// LIST helper (Vault accepts GET ?list=true, which survives proxies that drop LIST)
+func doLIST(client *http.Client, cfg Config, path string, out any) (int, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return doGET(client, cfg, path+sep+"list=true", out)
+}
+
+// PUT JSON helper with headers, decodes the response like doGET
+func doPUT(client *http.Client, cfg Config, path string, body any, out any) (int, error) {
+	url := strings.TrimRight(cfg.Addr, "/") + path
+	b, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+	req, err := NewRequestJSON(http.MethodPut, url, b)
+	if err != nil {
+		return 0, err
+	}
+	withVaultHeaders(req, cfg)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	code := res.StatusCode
+	if out != nil && code >= 200 && code <= 299 {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return code, err
+		}
+	}
+	return code, nil
+}
+
 func formatExpiry(exp string) string {
 	if exp == "" {
 		return ""
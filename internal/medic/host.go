@@ -0,0 +1,186 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	hostMemWarnPct  = 90.0 // used memory percent before we flag it
+	hostDiskWarnPct = 85.0 // used disk percent before we flag a partition
+)
+
+type hostInfoResp struct {
+	Data struct {
+		CPU []struct {
+			ModelName string `json:"modelName"`
+			Cores     int    `json:"cores"`
+		} `json:"cpu"`
+		Disk []struct {
+			Path        string  `json:"path"`
+			Fstype      string  `json:"fstype"`
+			Total       uint64  `json:"total"`
+			Free        uint64  `json:"free"`
+			Used        uint64  `json:"used"`
+			UsedPercent float64 `json:"usedPercent"`
+		} `json:"disk"`
+		Host struct {
+			Hostname string `json:"hostname"`
+			Uptime   int64  `json:"uptime"`
+			Platform string `json:"platform"`
+			Kernel   string `json:"kernelVersion"`
+		} `json:"host"`
+		Memory struct {
+			Total       uint64  `json:"total"`
+			Available   uint64  `json:"available"`
+			Used        uint64  `json:"used"`
+			UsedPercent float64 `json:"usedPercent"`
+		} `json:"memory"`
+	} `json:"data"`
+}
+
+// JSON shape for host resources
+type jsonHostDisk struct {
+	Path        string  `json:"path"`
+	Fstype      string  `json:"fstype,omitempty"`
+	TotalBytes  uint64  `json:"total_bytes"`
+	FreeBytes   uint64  `json:"free_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+type jsonHostInfo struct {
+	Hostname       string         `json:"hostname,omitempty"`
+	OS             string         `json:"os,omitempty"`
+	UptimeSeconds  int64          `json:"uptime_seconds"`
+	CPUCount       int            `json:"cpu_count"`
+	CPUModel       string         `json:"cpu_model,omitempty"`
+	MemTotalBytes  uint64         `json:"mem_total_bytes"`
+	MemAvailBytes  uint64         `json:"mem_available_bytes"`
+	MemUsedPercent float64        `json:"mem_used_percent"`
+	Disks          []jsonHostDisk `json:"disks,omitempty"`
+}
+
+func humanBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+func humanUptime(sec int64) string {
+	d := sec / 86400
+	h := (sec % 86400) / 3600
+	m := (sec % 3600) / 60
+	if d > 0 {
+		return fmt.Sprintf("%dd%dh", d, h)
+	}
+	return fmt.Sprintf("%dh%dm", h, m)
+}
+
+// pathUnder reports whether path lies on the filesystem mounted at mount.
+func pathUnder(path, mount string) bool {
+	if mount == "/" {
+		return strings.HasPrefix(path, "/")
+	}
+	return path == mount || strings.HasPrefix(path, strings.TrimSuffix(mount, "/")+"/")
+}
+
+func hostDiagnostics(client *http.Client, cfg Config, opt Options) []check {
+	diagnostics := []check{}
+	var hi hostInfoResp
+	code, err := doGET(client, cfg, "/v1/sys/host-info", &hi)
+	if err != nil || code != 200 {
+		if code == 403 {
+			diagnostics = append(diagnostics, check{"host.info", "Host info", true, "forbidden (insufficient perms)", nil})
+		}
+		return diagnostics
+	}
+	d := hi.Data
+	out := &jsonHostInfo{
+		Hostname:       d.Host.Hostname,
+		OS:             strings.TrimSpace(d.Host.Platform + " " + d.Host.Kernel),
+		UptimeSeconds:  d.Host.Uptime,
+		MemTotalBytes:  d.Memory.Total,
+		MemAvailBytes:  d.Memory.Available,
+		MemUsedPercent: d.Memory.UsedPercent,
+	}
+
+	// gopsutil reports one entry per logical CPU (cores=1) on Linux and one
+	// per socket (with its core count) on Windows and macOS
+	for _, c := range d.CPU {
+		if c.Cores > 0 {
+			out.CPUCount += c.Cores
+		} else {
+			out.CPUCount++
+		}
+	}
+	if len(d.CPU) > 0 {
+		out.CPUModel = strings.TrimSpace(d.CPU[0].ModelName)
+	}
+	if out.CPUCount > 0 {
+		diagnostics = append(diagnostics, check{"host.cpu", "Host CPU", true, fmt.Sprintf("%d x %s", out.CPUCount, out.CPUModel), nil})
+	}
+
+	if d.Memory.Total > 0 {
+		ok := d.Memory.UsedPercent < hostMemWarnPct
+		diagnostics = append(diagnostics, check{"host.memory", "Host memory", ok, fmt.Sprintf("used %s of %s (%.0f%%), available %s",
+			humanBytes(d.Memory.Used), humanBytes(d.Memory.Total), d.Memory.UsedPercent, humanBytes(d.Memory.Available)),
+			map[string]any{"total_bytes": d.Memory.Total, "used_bytes": d.Memory.Used,
+				"available_bytes": d.Memory.Available, "used_percent": d.Memory.UsedPercent}})
+		if !ok {
+			extraHints = append(extraHints, fmt.Sprintf("Host memory is %.0f%% used; Vault may be OOM-killed. Check lease counts, caching and co-located workloads.", d.Memory.UsedPercent))
+		}
+	}
+
+	// The sanitized config does not expose storage.path, so the data
+	// partition is only known when the user names it with --data-path
+	dataDisk := ""
+	if opt.DataPath != "" {
+		for _, disk := range d.Disk {
+			if pathUnder(opt.DataPath, disk.Path) && len(disk.Path) > len(dataDisk) {
+				dataDisk = disk.Path
+			}
+		}
+	}
+	for _, disk := range d.Disk {
+		out.Disks = append(out.Disks, jsonHostDisk{
+			Path:        disk.Path,
+			Fstype:      disk.Fstype,
+			TotalBytes:  disk.Total,
+			FreeBytes:   disk.Free,
+			UsedPercent: disk.UsedPercent,
+		})
+		ok := disk.UsedPercent < hostDiskWarnPct
+		detail := fmt.Sprintf("free %s of %s (%.0f%% used)", humanBytes(disk.Free), humanBytes(disk.Total), disk.UsedPercent)
+		if disk.Path == dataDisk {
+			detail += " — holds " + opt.DataPath
+		}
+		diagnostics = append(diagnostics, itemCheck("host.disk", disk.Path, "Host disk "+disk.Path, ok, detail).with(map[string]any{
+			"total_bytes": disk.Total, "free_bytes": disk.Free, "used_percent": disk.UsedPercent, "data_path": disk.Path == dataDisk}))
+		if !ok {
+			hint := fmt.Sprintf("Disk %s is %.0f%% full. If it holds the Vault data directory (raft), free space now: a full disk halts writes.", disk.Path, disk.UsedPercent)
+			if disk.Path == dataDisk {
+				hint = fmt.Sprintf("Disk %s holds the Vault data directory and is %.0f%% full; free space now: a full disk halts writes.", disk.Path, disk.UsedPercent)
+			}
+			extraHints = append(extraHints, hint)
+		}
+	}
+
+	if d.Host.Uptime > 0 {
+		detail := humanUptime(d.Host.Uptime)
+		if out.Hostname != "" {
+			detail = fmt.Sprintf("%s (%s)", detail, out.Hostname)
+		}
+		diagnostics = append(diagnostics, check{"host.uptime", "Host uptime", true, detail, nil})
+	}
+
+	jsonHost = out
+	return diagnostics
+}
@@ -0,0 +1,120 @@
+package medic
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestsuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestsuite `xml:"testsuite"`
+}
+
+type junitTestsuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestcase `xml:"testcase"`
+}
+
+type junitTestcase struct {
+	Name       string           `xml:"name,attr"`
+	Classname  string           `xml:"classname,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitFailure    `xml:"failure,omitempty"`
+	Skipped    *junitSkipped    `xml:"skipped,omitempty"`
+	SystemOut  string           `xml:"system-out,omitempty"`
+}
+
+type junitProperties struct {
+	Items []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// skippedDetail reports checks that did not run: missing permissions,
+// endpoints absent on this version, or checks gated off.
+func skippedDetail(detail string) bool {
+	d := strings.ToLower(detail)
+	return strings.Contains(d, "forbidden") ||
+		strings.HasPrefix(d, "skipped") ||
+		strings.HasPrefix(d, "not available")
+}
+
+// junitSuite turns one report section into a testsuite, one testcase per
+// check named by its ID. Only failing results (which set the exit code) are
+// <failure>s; warnings from diagnostics and telemetry pass, with the
+// severity as a property and the detail in <system-out>.
+func junitSuite(name string, rows []jsonCheck, ts string) junitTestsuite {
+	s := junitTestsuite{Name: name, Timestamp: ts}
+	keys := checkKeys(rows)
+	for i, c := range rows {
+		tc := junitTestcase{Name: keys[i], Classname: name, Properties: &junitProperties{Items: []junitProperty{
+			{"name", c.Name},
+			{"severity", c.Severity},
+		}}}
+		switch {
+		case c.Severity == sevSkipped:
+			tc.Skipped = &junitSkipped{Message: c.Detail}
+			s.Skipped++
+		case c.Severity == sevCritical:
+			tc.Failure = &junitFailure{Message: c.Detail, Type: "error", Text: c.Detail}
+			s.Failures++
+		case c.Severity == sevWarning:
+			tc.SystemOut = "WARNING: " + c.Detail
+		default:
+			tc.SystemOut = c.Detail
+		}
+		s.Cases = append(s.Cases, tc)
+		s.Tests++
+	}
+	return s
+}
+
+func renderJUnit(w io.Writer, r jsonResult) {
+	ts := time.Unix(r.Timestamp, 0).UTC().Format("2006-01-02T15:04:05")
+	out := junitTestsuites{Name: "vault_doctor medic"}
+	if r.ClusterName != "" {
+		out.Name += " (" + r.ClusterName + ")"
+	}
+	out.Suites = append(out.Suites, junitSuite("medic.results", r.Checks, ts))
+	if len(r.Diagnostics) > 0 {
+		out.Suites = append(out.Suites, junitSuite("medic.diagnostics", r.Diagnostics, ts))
+	}
+	if len(r.Telemetry) > 0 {
+		out.Suites = append(out.Suites, junitSuite("medic.telemetry", r.Telemetry, ts))
+	}
+	for _, s := range out.Suites {
+		out.Tests += s.Tests
+		out.Failures += s.Failures
+		out.Skipped += s.Skipped
+	}
+
+	fmt.Fprint(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	_ = enc.Encode(out)
+	fmt.Fprintln(w)
+}
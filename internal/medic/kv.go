@@ -0,0 +1,76 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Vault keeps this many versions when max_versions is 0
+const kvDefaultMaxVersions = 10
+
+type kvConfigResp struct {
+	Data struct {
+		MaxVersions        int    `json:"max_versions"`
+		CASRequired        bool   `json:"cas_required"`
+		DeleteVersionAfter string `json:"delete_version_after"`
+	} `json:"data"`
+}
+
+// kvCASRequired reports whether a mount matches one of the --kv-cas-mounts globs.
+func kvCASRequired(mount string, globs []string) bool {
+	name := strings.Trim(mount, "/")
+	for _, g := range globs {
+		if ok, _ := path.Match(strings.Trim(g, "/"), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// KV v2 engine config vs. the configured policy thresholds.
+func kvConfigDiagnostics(client *http.Client, cfg Config, mount string, opt Options) []check {
+	diagnostics := []check{}
+	var kc kvConfigResp
+	code, err := doGET(client, cfg, "/v1/"+strings.TrimSuffix(mount, "/")+"/config", &kc)
+	if err != nil || code != 200 {
+		if code == 403 {
+			diagnostics = append(diagnostics, itemCheck("kv.config", mount, "KV config "+mount, true, "forbidden (insufficient perms)"))
+		}
+		return diagnostics
+	}
+
+	maxVersions := kc.Data.MaxVersions
+	mv := fmt.Sprintf("%d", maxVersions)
+	if maxVersions == 0 {
+		maxVersions = kvDefaultMaxVersions
+		mv = fmt.Sprintf("0 (default %d)", kvDefaultMaxVersions)
+	}
+	dva := strings.TrimSpace(kc.Data.DeleteVersionAfter)
+	if dva == "" {
+		dva = "0s"
+	}
+	detail := fmt.Sprintf("max_versions=%s cas_required=%v delete_version_after=%s", mv, kc.Data.CASRequired, dva)
+
+	violations := []string{}
+	if opt.KVMaxVersions > 0 && maxVersions > opt.KVMaxVersions {
+		violations = append(violations, fmt.Sprintf("max_versions must be <= %d", opt.KVMaxVersions))
+	}
+	if !kc.Data.CASRequired && kvCASRequired(mount, opt.KVCASMounts) {
+		violations = append(violations, "cas_required must be true")
+	}
+	if opt.KVDeleteAfterMax > 0 {
+		d, err := time.ParseDuration(dva)
+		if err != nil || d <= 0 || d > opt.KVDeleteAfterMax {
+			violations = append(violations, fmt.Sprintf("delete_version_after must be set and <= %s", opt.KVDeleteAfterMax))
+		}
+	}
+	if len(violations) > 0 {
+		detail += " — " + strings.Join(violations, "; ")
+		extraHints = append(extraHints, fmt.Sprintf("KV mount %s drifted from policy; fix with 'vault write %sconfig ...' (%s).", mount, mount, strings.Join(violations, "; ")))
+	}
+	diagnostics = append(diagnostics, itemCheck("kv.config", mount, "KV config "+mount, len(violations) == 0, detail))
+	return diagnostics
+}
@@ -0,0 +1,296 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Walk limits: a lease explosion must not turn the doctor into one.
+const (
+	leaseListBudget  = 500   // max LIST calls under /sys/leases/lookup
+	leaseLookupLimit = 100   // max lease lookups used for the expiry histogram
+	leaseMountWarn   = 10000 // leases on a single mount before we flag it
+	leaseTopMounts   = 5     // per-mount rows shown in diagnostics
+	irrevocableShown = 5     // irrevocable lease rows shown in diagnostics
+)
+
+type listResp struct {
+	Data struct {
+		Keys []string `json:"keys"`
+	} `json:"data"`
+}
+
+type leaseCountResp struct {
+	Data struct {
+		LeaseCount int            `json:"lease_count"`
+		Counts     map[string]int `json:"counts"`
+	} `json:"data"`
+}
+
+type irrevocableLease struct {
+	LeaseID    string `json:"lease_id"`
+	Namespace  string `json:"namespace"`
+	Error      string `json:"error"`
+	ExpireTime string `json:"expire_time"`
+}
+
+type irrevocableListResp struct {
+	Data struct {
+		LeaseCount int                `json:"lease_count"`
+		Leases     []irrevocableLease `json:"leases"`
+	} `json:"data"`
+}
+
+type leaseLookupResp struct {
+	Data struct {
+		ID         string `json:"id"`
+		ExpireTime string `json:"expire_time"`
+		TTL        int64  `json:"ttl"`
+	} `json:"data"`
+}
+
+// Expiry histogram buckets (upper bounds, exclusive)
+var leaseBuckets = []struct {
+	label string
+	upTo  time.Duration
+}{
+	{"<1h", time.Hour},
+	{"1h-24h", 24 * time.Hour},
+	{"1d-7d", 7 * 24 * time.Hour},
+	{"7d-30d", 30 * 24 * time.Hour},
+	{">30d", 0},
+}
+
+// mountPrefixes returns secret mount paths and auth mount paths (as "auth/<path>").
+func mountPrefixes(client *http.Client, cfg Config) []string {
+	type mountList struct {
+		Data map[string]struct {
+			Type string `json:"type"`
+		} `json:"data"`
+	}
+	out := []string{}
+	var m mountList
+	if code, err := doGET(client, cfg, "/v1/sys/mounts", &m); err == nil && code == 200 {
+		for p := range m.Data {
+			if p != "" {
+				out = append(out, p)
+			}
+		}
+	}
+	var a mountList
+	if code, err := doGET(client, cfg, "/v1/sys/auth", &a); err == nil && code == 200 {
+		for p := range a.Data {
+			if p != "" {
+				out = append(out, "auth/"+p)
+			}
+		}
+	}
+	return out
+}
+
+// mountForLease attributes a lease ID to the longest matching mount prefix,
+// falling back to its first path segment.
+func mountForLease(id string, mounts []string) string {
+	best := ""
+	for _, m := range mounts {
+		if strings.HasPrefix(id, m) && len(m) > len(best) {
+			best = m
+		}
+	}
+	if best != "" {
+		return best
+	}
+	if i := strings.Index(id, "/"); i >= 0 {
+		return id[:i+1]
+	}
+	return id
+}
+
+// walkLeases lists lease IDs under prefix depth-first, spending one unit of
+// budget per LIST call. It returns the outcome of the first LIST.
+func walkLeases(client *http.Client, cfg Config, prefix string, budget *int, ids *[]string) (int, error) {
+	if *budget <= 0 {
+		return 0, nil
+	}
+	*budget--
+	var lr listResp
+	code, err := doLIST(client, cfg, "/v1/sys/leases/lookup/"+prefix, &lr)
+	if err != nil || code != 200 {
+		return code, err
+	}
+	for _, k := range lr.Data.Keys {
+		if strings.HasSuffix(k, "/") {
+			walkLeases(client, cfg, prefix+k, budget, ids)
+			continue
+		}
+		*ids = append(*ids, prefix+k)
+	}
+	return code, nil
+}
+
+func leaseDiagnostics(client *http.Client, cfg Config) []check {
+	diagnostics := []check{}
+	leaseCountsByMount = nil
+	leaseCountsPartial = false
+
+	// Totals via sys/leases/count: one call however many leases there are
+	total, totalKnown := 0, false
+	var all leaseCountResp
+	if code, err := doGET(client, cfg, "/v1/sys/leases/count?type=all", &all); err == nil && code == 200 {
+		total, totalKnown = all.Data.LeaseCount, true
+	}
+	withChildren := 0
+	if totalKnown {
+		var nested leaseCountResp
+		if code, err := doGET(client, cfg, "/v1/sys/leases/count?type=all&include_child_namespaces=true", &nested); err == nil && code == 200 {
+			withChildren = nested.Data.LeaseCount
+		}
+	}
+
+	// Per-mount split via LIST /sys/leases/lookup, within the walk budget
+	ids := []string{}
+	budget := leaseListBudget
+	code, err := walkLeases(client, cfg, "", &budget, &ids)
+	switch {
+	case code == 403 && !totalKnown:
+		diagnostics = append(diagnostics, check{"lease.inventory", "Leases", true, "forbidden (insufficient perms)", nil})
+	case code == 403:
+		diagnostics = append(diagnostics, check{"lease.inventory", "Leases", true, fmt.Sprintf("total=%d; per-mount split forbidden (insufficient perms)", total),
+			map[string]any{"total": total, "total_exact": true}})
+	case err == nil && (code == 200 || code == 404):
+		// 404 on the root prefix simply means there are no leases
+		mounts := mountPrefixes(client, cfg)
+		leaseCountsByMount = map[string]int{}
+		for _, id := range ids {
+			leaseCountsByMount[mountForLease(id, mounts)]++
+		}
+		leaseCountsPartial = budget <= 0
+		if !totalKnown {
+			total = len(ids)
+		}
+		detail := fmt.Sprintf("total=%d across %d mount(s)", total, len(leaseCountsByMount))
+		switch {
+		case leaseCountsPartial && totalKnown:
+			detail += fmt.Sprintf(" (per-mount split partial: walk stopped after %d LISTs)", leaseListBudget)
+		case leaseCountsPartial:
+			detail += " (walk truncated, counts are a lower bound)"
+		}
+		if withChildren > total {
+			detail += fmt.Sprintf("; %d incl. child namespaces", withChildren)
+		}
+		data := map[string]any{"total": total, "total_exact": totalKnown, "by_mount": leaseCountsByMount, "by_mount_partial": leaseCountsPartial}
+		if withChildren > 0 {
+			data["total_with_child_namespaces"] = withChildren
+		}
+		diagnostics = append(diagnostics, check{"lease.inventory", "Leases", true, detail, data})
+
+		paths := make([]string, 0, len(leaseCountsByMount))
+		for p := range leaseCountsByMount {
+			paths = append(paths, p)
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			if leaseCountsByMount[paths[i]] != leaseCountsByMount[paths[j]] {
+				return leaseCountsByMount[paths[i]] > leaseCountsByMount[paths[j]]
+			}
+			return paths[i] < paths[j]
+		})
+		for i, p := range paths {
+			n := leaseCountsByMount[p]
+			if i >= leaseTopMounts && n < leaseMountWarn {
+				continue
+			}
+			ok := n < leaseMountWarn
+			detail := fmt.Sprintf("%d", n)
+			if leaseCountsPartial {
+				detail = fmt.Sprintf("≥%d (partial)", n)
+			}
+			diagnostics = append(diagnostics, itemCheck("lease.mount", p, "Leases "+p, ok, detail))
+			if !ok {
+				extraHints = append(extraHints, fmt.Sprintf("Mount %s holds %d leases; check client TTLs and lease reuse (possible lease explosion).", p, n))
+			}
+		}
+
+		if len(ids) > 0 {
+			diagnostics = append(diagnostics, check{"lease.expiry", "Lease expiry", true, leaseHistogram(client, cfg, ids), nil})
+		}
+	default:
+		why := fmt.Sprintf("HTTP %d", code)
+		if err != nil {
+			why = err.Error()
+		}
+		if !totalKnown {
+			diagnostics = append(diagnostics, check{"lease.inventory", "Leases", true, "not available (" + why + ")", nil})
+			break
+		}
+		diagnostics = append(diagnostics, check{"lease.inventory", "Leases", true, fmt.Sprintf("total=%d; per-mount split not available (%s)", total, why),
+			map[string]any{"total": total, "total_exact": true}})
+	}
+
+	// Irrevocable leases
+	var lc leaseCountResp
+	if code, err := doGET(client, cfg, "/v1/sys/leases/count?type=irrevocable", &lc); err == nil && code == 200 {
+		n := lc.Data.LeaseCount
+		diagnostics = append(diagnostics, check{"lease.irrevocable_count", "Irrevocable leases", n == 0, fmt.Sprintf("%d", n), map[string]any{"count": n}})
+		if n > 0 {
+			extraHints = append(extraHints, "Irrevocable leases found. Fix the backend error, then revoke with 'vault lease revoke -force -prefix <prefix>'.")
+			var il irrevocableListResp
+			if code, err := doGET(client, cfg, fmt.Sprintf("/v1/sys/leases?type=irrevocable&limit=%d", irrevocableShown), &il); err == nil && code == 200 {
+				for i, l := range il.Data.Leases {
+					if i >= irrevocableShown {
+						break
+					}
+					msg := strings.TrimSpace(l.Error)
+					if msg == "" {
+						msg = "no error recorded"
+					}
+					diagnostics = append(diagnostics, check{"lease.irrevocable", "Irrevocable lease", false, fmt.Sprintf("%s: %s", l.LeaseID, msg), nil})
+				}
+			}
+		}
+	} else if code == 403 {
+		diagnostics = append(diagnostics, check{"lease.irrevocable_count", "Irrevocable leases", true, "forbidden (insufficient perms)", nil})
+	}
+
+	return diagnostics
+}
+
+// leaseHistogram looks up an evenly spread sample of leases and buckets their
+// remaining TTL.
+func leaseHistogram(client *http.Client, cfg Config, ids []string) string {
+	step := 1
+	if len(ids) > leaseLookupLimit {
+		step = (len(ids) + leaseLookupLimit - 1) / leaseLookupLimit
+	}
+	counts := make([]int, len(leaseBuckets))
+	sampled := 0
+	for i := 0; i < len(ids); i += step {
+		var lr leaseLookupResp
+		code, err := doPUT(client, cfg, "/v1/sys/leases/lookup", map[string]string{"lease_id": ids[i]}, &lr)
+		if err != nil || code != 200 {
+			continue
+		}
+		sampled++
+		ttl := time.Duration(lr.Data.TTL) * time.Second
+		for b, bucket := range leaseBuckets {
+			if bucket.upTo == 0 || ttl < bucket.upTo {
+				counts[b]++
+				break
+			}
+		}
+	}
+	if sampled == 0 {
+		return "lookup failed (insufficient perms?)"
+	}
+	parts := make([]string, 0, len(leaseBuckets))
+	for b, bucket := range leaseBuckets {
+		parts = append(parts, fmt.Sprintf("%s=%d", bucket.label, counts[b]))
+	}
+	detail := strings.Join(parts, " ")
+	if sampled < len(ids) {
+		detail += fmt.Sprintf(" (sampled %d of %d)", sampled, len(ids))
+	}
+	return detail
+}
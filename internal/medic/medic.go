@@ -3,86 +3,117 @@ package medic
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 )
 
+// where structured reports are written; bundle captures the JSON report
+var reportOut io.Writer = os.Stdout
+
 func mustJSONEncoder() *json.Encoder {
-	enc := json.NewEncoder(os.Stdout)
+	enc := json.NewEncoder(reportOut)
 	enc.SetIndent("", "  ")
 	return enc
 }
 
 func Run(opt Options) int {
+	runStarted = time.Now()
+	if opt.format() == "template" {
+		// fail before touching Vault if the template is unusable
+		t, err := loadReportTemplate(opt.Template, opt)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		reportTemplate = t
+	}
+	if opt.format() == "nagios" {
+		l, err := parseNagiosThresholds(opt.Warn, opt.Crit)
+		if err != nil {
+			fmt.Printf("VAULT UNKNOWN - %v\n", err)
+			return nagiosUnknown
+		}
+		nagiosLimits = l
+	}
 	printBanner(opt.Version, opt)
 
 	// env
 	loadDotEnvIfPresent(".env")
-	cfg := LoadConfigFromEnv()
+	return run(LoadConfigFromEnv(), opt)
+}
 
+// run is the medic itself, after options and config are resolved.
+func run(cfg Config, opt Options) int {
 	results := []check{}
 
 	// VAULT_ADDR present
 	if cfg.Addr == "" {
-		results = append(results, check{"VAULT_ADDR present", false, "not set"})
+		results = append(results, check{"env.vault_addr", "VAULT_ADDR present", false, "not set", nil})
 		return finish(results, 0, nil, 0, cfg, nil, opt)
 	}
-	results = append(results, check{"VAULT_ADDR present", true, cfg.Addr})
+	results = append(results, check{"env.vault_addr", "VAULT_ADDR present", true, cfg.Addr, nil})
 
 	client := NewHTTPClient(cfg.SkipVerify)
 
 	// Auth: token or AppRole
 	if cfg.Token == "" && cfg.RoleID != "" && cfg.SecretID != "" {
+		st := startStep("checks", "AppRole login", len(results))
 		token, err := approleLogin(client, cfg)
 		if err != nil {
-			results = append(results, check{"AppRole login", false, err.Error()})
+			results = append(results, check{"auth.approle_login", "AppRole login", false, err.Error(), nil})
+			st.done(results)
 			return finish(results, 0, nil, 0, cfg, nil, opt)
 		}
 		cfg.Token = token
-		results = append(results, check{"AppRole login", true, "received client token"})
+		registerSecret(token)
+		results = append(results, check{"auth.approle_login", "AppRole login", true, "received client token", nil})
+		st.done(results)
 	} else if cfg.Token != "" {
-		results = append(results, check{"VAULT_TOKEN present", true, "token provided"})
+		results = append(results, check{"auth.token_present", "VAULT_TOKEN present", true, "token provided", nil})
 	} else {
-		results = append(results, check{"Auth configuration", false, "provide VAULT_TOKEN or VAULT_ROLE_ID + VAULT_SECRET_ID"})
+		results = append(results, check{"auth.configuration", "Auth configuration", false, "provide VAULT_TOKEN or VAULT_ROLE_ID + VAULT_SECRET_ID", nil})
 		return finish(results, 0, nil, 0, cfg, nil, opt)
 	}
 
 	// Health
+	st := startStep("checks", "Health and license", len(results))
 	health, status, err := vaultHealth(client, cfg)
 	if err != nil {
-		results = append(results, check{"API reachability", false, fmt.Sprintf("%v", err)})
+		results = append(results, check{"api.reachability", "API reachability", false, fmt.Sprintf("%v", err), nil})
+		st.done(results)
 		return finish(results, status, health, status, cfg, nil, opt)
 	}
-	results = append(results, check{"API reachability", true, fmt.Sprintf("%s (HTTP %d)", healthMode(status), status)})
+	results = append(results, check{"api.reachability", "API reachability", true, fmt.Sprintf("%s (HTTP %d)", healthMode(status), status), nil})
 
 	if health != nil {
-		results = append(results, check{"Initialized", health.Initialized, fmt.Sprintf("%v", health.Initialized)})
-		results = append(results, check{"Sealed", !health.Sealed, fmt.Sprintf("sealed=%v", health.Sealed)})
+		results = append(results, check{"health.initialized", "Initialized", health.Initialized, fmt.Sprintf("%v", health.Initialized), nil})
+		results = append(results, check{"health.sealed", "Sealed", !health.Sealed, fmt.Sprintf("sealed=%v", health.Sealed), nil})
 		if health.Standby != nil {
-			results = append(results, check{"Standby mode", !*health.Standby, fmt.Sprintf("standby=%v", *health.Standby)})
+			results = append(results, check{"health.standby", "Standby mode", !*health.Standby, fmt.Sprintf("standby=%v", *health.Standby), nil})
 		}
 		if health.ClusterName != "" {
-			results = append(results, check{"Cluster name", true, health.ClusterName})
+			results = append(results, check{"health.cluster_name", "Cluster name", true, health.ClusterName, nil})
 		}
 		if health.ServerTimeUTC != 0 {
-			results = append(results, check{"Server time", true, fmt.Sprintf("%d", health.ServerTimeUTC)})
+			results = append(results, check{"health.server_time", "Server time", true, fmt.Sprintf("%d", health.ServerTimeUTC), nil})
 		}
 
 		// ---- Enterprise detection + License status ----
 		// ---- Enterprise detection + License status (guarded) ----
 		if strings.Contains(health.Version, "+ent") {
-			results = append(results, check{"Vault version", true, fmt.Sprintf("%s (enterprise detected)", health.Version)})
+			results = append(results, check{"health.version", "Vault version", true, fmt.Sprintf("%s (enterprise detected)", health.Version), nil})
 
 			if lic, lcode, lerr := vaultLicenseStatus(client, cfg); lerr != nil {
-				results = append(results, check{"License status", false, fmt.Sprintf("error: %v", lerr)})
+				results = append(results, check{"license.status", "License status", false, fmt.Sprintf("error: %v", lerr), nil})
 			} else {
 				switch lcode {
 				case http.StatusForbidden:
-					results = append(results, check{"License status", false, "forbidden (insufficient perms)"})
+					results = append(results, check{"license.status", "License status", false, "forbidden (insufficient perms)", nil})
 				case http.StatusNotFound:
-					results = append(results, check{"License status", false, "not available (endpoint disabled or OSS-like behavior)"})
+					results = append(results, check{"license.status", "License status", false, "not available (endpoint disabled or OSS-like behavior)", nil})
 				case http.StatusOK:
 					// Only show a “state” row if we actually have content
 					state := strings.TrimSpace(lic.State)
@@ -90,37 +121,40 @@ func Run(opt Options) int {
 					hasFeatures := len(lic.Features) > 0
 					switch {
 					case state == "" && exp == "" && !hasFeatures:
-						results = append(results, check{"License status", true, "available, no details reported"})
+						results = append(results, check{"license.status", "License status", true, "available, no details reported", nil})
 					default:
-						results = append(results, check{"License state", true,
+						results = append(results, check{"license.state", "License state", true,
 							fmt.Sprintf("%s%s%s",
 								state,
 								formatExpiry(exp),
 								formatFeatures(lic.Features),
 							),
+							nil,
 						})
 					}
 				default:
-					results = append(results, check{"License status", false, fmt.Sprintf("unexpected HTTP %d", lcode)})
+					results = append(results, check{"license.status", "License status", false, fmt.Sprintf("unexpected HTTP %d", lcode), nil})
 				}
 			}
 		} else {
-			results = append(results, check{"Vault version", true, health.Version})
+			results = append(results, check{"health.version", "Vault version", true, health.Version, nil})
 		}
 
 	} else {
-		results = append(results, check{"Health payload", false, "no JSON body returned"})
+		results = append(results, check{"health.payload", "Health payload", false, "no JSON body returned", nil})
 	}
+	st.done(results)
 
 	// Optionally prompt to unseal
-	if health != nil && health.Sealed && !opt.JSON && !opt.Quiet {
-		if err := promptUnseal(client, cfg, opt); err != nil && !opt.Quiet && !opt.JSON {
+	if health != nil && health.Sealed && !opt.structured() && !opt.Quiet {
+		if err := promptUnseal(client, cfg, opt); err != nil && !opt.Quiet && !opt.structured() {
 			fmt.Printf("%sUnseal attempt failed: %v%s\n", cwrap("", colRed, opt), err, colReset)
 		}
 		time.Sleep(500 * time.Millisecond)
 		newHealth, newStatus, err := vaultHealth(client, cfg)
 		if err == nil && newHealth != nil && !newHealth.Sealed {
-			diags := runDiagnostics(client, cfg, newHealth)
+			diags := runDiagnostics(client, cfg, newHealth, opt)
+			results = append(results, baselineResults(client, cfg, newHealth, opt)...)
 			return finish(results, newStatus, newHealth, newStatus, cfg, diags, opt)
 		}
 	}
@@ -128,7 +162,16 @@ func Run(opt Options) int {
 	// Normal finish with diagnostics (if unsealed)
 	var diags []check
 	if health != nil && !health.Sealed {
-		diags = runDiagnostics(client, cfg, health)
+		diags = runDiagnostics(client, cfg, health, opt)
+		results = append(results, baselineResults(client, cfg, health, opt)...)
+	} else if health != nil && health.Sealed {
+		// sealed: seal status is all we can usefully read
+		st := startStep("diagnostics", "Seal status", 0)
+		diags = sealDiagnostics(client, cfg)
+		st.done(diags)
+		if jsonSealType != "" && jsonSealType != "shamir" {
+			extraHints = append(extraHints, "Auto-unseal node is still sealed, so its key service is likely unreachable. "+kmsHint(jsonSealType, ""))
+		}
 	}
 	return finish(results, status, health, status, cfg, diags, opt)
 }
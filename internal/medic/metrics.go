@@ -0,0 +1,278 @@
+package medic
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Telemetry thresholds (latencies in ms, as Vault reports them)
+const (
+	telemRequestP99Warn   = 500.0
+	telemLeasesWarn       = 100000.0
+	telemGoroutinesWarn   = 20000.0
+	telemBarrierP99Warn   = 100.0
+	telemStorageP99Warn   = 100.0
+	telemLastContactWarn  = 200.0
+	telemQuantileWanted   = "0.99"
+	telemFallbackStatName = "max" // JSON form has no quantiles; use the interval max
+)
+
+var storageOpMetric = regexp.MustCompile(`^vault_(raft_storage|consul|file|inmem|dynamodb|etcd|gcs|mysql|postgres|s3|spanner|zookeeper|azure|cassandra|cockroachdb|couchdb|swift|oci)_(get|put|list|delete)$`)
+
+type metricPoint struct {
+	labels map[string]string
+	value  float64
+}
+
+// metricSet maps normalised metric names (vault_core_handle_request) to points.
+type metricSet map[string][]metricPoint
+
+type metricsJSONResp struct {
+	Gauges []struct {
+		Name   string            `json:"Name"`
+		Value  float64           `json:"Value"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Gauges"`
+	Counters []struct {
+		Name   string            `json:"Name"`
+		Sum    float64           `json:"Sum"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Counters"`
+	Samples []struct {
+		Name   string            `json:"Name"`
+		Max    float64           `json:"Max"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Samples"`
+}
+
+func normMetricName(s string) string {
+	return strings.NewReplacer(".", "_", "-", "_").Replace(s)
+}
+
+// parsePrometheus reads the text exposition format (no external deps).
+func parsePrometheus(body []byte) metricSet {
+	out := metricSet{}
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, rest := line, ""
+		labels := map[string]string{}
+		if i := strings.IndexByte(line, '{'); i >= 0 {
+			j := strings.LastIndexByte(line, '}')
+			if j < i {
+				continue
+			}
+			name = line[:i]
+			labels = parseLabels(line[i+1 : j])
+			rest = strings.TrimSpace(line[j+1:])
+		} else if i := strings.IndexAny(line, " \t"); i >= 0 {
+			name = line[:i]
+			rest = strings.TrimSpace(line[i+1:])
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil || math.IsNaN(v) {
+			continue
+		}
+		out[name] = append(out[name], metricPoint{labels: labels, value: v})
+	}
+	return out
+}
+
+func parseLabels(s string) map[string]string {
+	labels := map[string]string{}
+	for len(s) > 0 {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || eq+1 >= len(s) || s[eq+1] != '"' {
+			break
+		}
+		key := strings.TrimSpace(strings.TrimLeft(s[:eq], ","))
+		var val strings.Builder
+		i := eq + 2
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			val.WriteByte(s[i])
+		}
+		labels[key] = val.String()
+		s = s[min(i+1, len(s)):]
+	}
+	return labels
+}
+
+func metricsFromJSON(m metricsJSONResp) metricSet {
+	out := metricSet{}
+	for _, g := range m.Gauges {
+		n := normMetricName(g.Name)
+		out[n] = append(out[n], metricPoint{labels: g.Labels, value: g.Value})
+	}
+	for _, c := range m.Counters {
+		n := normMetricName(c.Name)
+		out[n] = append(out[n], metricPoint{labels: c.Labels, value: c.Sum})
+	}
+	for _, s := range m.Samples {
+		n := normMetricName(s.Name)
+		labels := map[string]string{"stat": telemFallbackStatName}
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		out[n] = append(out[n], metricPoint{labels: labels, value: s.Max})
+	}
+	return out
+}
+
+// sum adds up every series of a gauge/counter, ignoring summary quantiles.
+func (ms metricSet) sum(name string) (float64, bool) {
+	pts, ok := ms[name]
+	if !ok {
+		return 0, false
+	}
+	total := 0.0
+	for _, p := range pts {
+		if _, q := p.labels["quantile"]; q {
+			continue
+		}
+		total += p.value
+	}
+	return total, true
+}
+
+// p99 returns the 0.99 quantile of a timer (or the interval max from the
+// JSON form) and the label describing which one it is.
+func (ms metricSet) p99(name string) (float64, string, bool) {
+	best, label, found := 0.0, "", false
+	for _, p := range ms[name] {
+		switch {
+		case p.labels["quantile"] == telemQuantileWanted:
+			label = "p99"
+		case p.labels["stat"] == telemFallbackStatName:
+			label = "max"
+		default:
+			continue
+		}
+		if !found || p.value > best {
+			best, found = p.value, true
+		}
+	}
+	return best, label, found
+}
+
+// worstP99 is p99 across several timers, reporting which one was slowest.
+func (ms metricSet) worstP99(match func(string) bool) (float64, string, string, bool) {
+	best, bestName, bestLabel, found := 0.0, "", "", false
+	for name := range ms {
+		if !match(name) {
+			continue
+		}
+		if v, label, ok := ms.p99(name); ok && (!found || v > best) {
+			best, bestName, bestLabel, found = v, name, label, true
+		}
+	}
+	return best, bestName, bestLabel, found
+}
+
+func fetchMetrics(client *http.Client, cfg Config) (metricSet, string, int) {
+	code, body, err := doGETRaw(client, cfg, "/v1/sys/metrics?format=prometheus")
+	if err == nil && code == 200 {
+		return parsePrometheus(body), "prometheus", code
+	}
+	if code == 403 {
+		return nil, "", code
+	}
+	// prometheus_retention_time unset → 400; fall back to the JSON form
+	var mj metricsJSONResp
+	jcode, err := doGET(client, cfg, "/v1/sys/metrics", &mj)
+	if err != nil || jcode != 200 {
+		return nil, "", jcode
+	}
+	return metricsFromJSON(mj), "json", jcode
+}
+
+func telemetryDiagnostics(client *http.Client, cfg Config) []check {
+	out := []check{}
+	ms, format, code := fetchMetrics(client, cfg)
+	if ms == nil {
+		if code == 403 {
+			out = append(out, check{"telemetry.metrics", "Metrics", true, "forbidden (insufficient perms)", nil})
+		} else if code != 0 {
+			out = append(out, check{"telemetry.metrics", "Metrics", true, fmt.Sprintf("not available (HTTP %d)", code), nil})
+		}
+		return out
+	}
+	out = append(out, check{"telemetry.metrics", "Metrics", true, fmt.Sprintf("%d series (%s)", len(ms), format), nil})
+
+	latency := func(id, name, metric string, warn float64, hint string) {
+		if v, label, ok := ms.p99(metric); ok {
+			c := check{id, name, v < warn, fmt.Sprintf("%s=%.1fms", label, v),
+				map[string]any{"value": v, "unit": "ms", "stat": label, "warn": warn}}
+			if !c.ok {
+				c.detail += fmt.Sprintf(" (> %.0fms)", warn)
+				extraHints = append(extraHints, hint)
+			}
+			out = append(out, c)
+		}
+	}
+	gauge := func(id, name, metric string, warn float64, hint string) {
+		if v, ok := ms.sum(metric); ok {
+			c := check{id, name, v < warn, fmt.Sprintf("%.0f", v), map[string]any{"value": v, "warn": warn}}
+			if !c.ok {
+				c.detail += fmt.Sprintf(" (> %.0f)", warn)
+				extraHints = append(extraHints, hint)
+			}
+			out = append(out, c)
+		}
+	}
+
+	latency("telemetry.request_latency", "Request latency", "vault_core_handle_request", telemRequestP99Warn,
+		"Request handling is slow; check storage latency, audit devices and host load.")
+	gauge("telemetry.leases", "Leases (metric)", "vault_expire_num_leases", telemLeasesWarn,
+		"vault.expire.num_leases is high; look for clients creating leases without reuse.")
+	gauge("telemetry.goroutines", "Goroutines", "vault_runtime_num_goroutines", telemGoroutinesWarn,
+		"Goroutine count is high; possible request pile-up or slow storage.")
+
+	if v, name, label, ok := ms.worstP99(func(n string) bool { return strings.HasPrefix(n, "vault_barrier_") }); ok {
+		c := check{"telemetry.barrier_latency", "Barrier latency", v < telemBarrierP99Warn, fmt.Sprintf("%s=%.1fms (%s)", label, v, strings.TrimPrefix(name, "vault_")), nil}
+		if !c.ok {
+			extraHints = append(extraHints, "Barrier operations are slow; encryption is cheap, so this usually points at storage.")
+		}
+		out = append(out, c)
+	}
+	if v, name, label, ok := ms.worstP99(storageOpMetric.MatchString); ok {
+		c := check{"telemetry.storage_latency", "Storage latency", v < telemStorageP99Warn, fmt.Sprintf("%s=%.1fms (%s)", label, v, strings.TrimPrefix(name, "vault_")), nil}
+		if !c.ok {
+			extraHints = append(extraHints, "Storage backend operations are slow; check disk IOPS (raft) or backend health.")
+		}
+		out = append(out, c)
+	}
+	if jsonStorage == "" || jsonStorage == "raft" {
+		latency("telemetry.raft_last_contact", "Raft last contact", "vault_raft_leader_lastContact", telemLastContactWarn,
+			"Followers see slow leader contact; check network latency between raft peers.")
+	}
+
+	req, okReq := ms.sum("vault_audit_log_request_failure")
+	resp, okResp := ms.sum("vault_audit_log_response_failure")
+	if okReq || okResp {
+		failed := req + resp
+		out = append(out, check{"telemetry.audit_failures", "Audit failures", failed == 0, fmt.Sprintf("request=%.0f response=%.0f", req, resp),
+			map[string]any{"request": req, "response": resp}})
+		if failed > 0 {
+			extraHints = append(extraHints, "Audit log writes are failing; Vault blocks requests when no audit device succeeds. Check audit device targets.")
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,193 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Mounts with a max lease TTL above this are flagged.
+const mountMaxTTLWarn = 90 * 24 * time.Hour
+
+// Request/response keys that should always be HMAC'd in audit logs.
+var sensitiveAuditKeys = map[string]bool{
+	"password":      true,
+	"secret":        true,
+	"secret_id":     true,
+	"token":         true,
+	"client_token":  true,
+	"private_key":   true,
+	"plaintext":     true,
+	"unseal_key":    true,
+	"recovery_key":  true,
+	"access_key":    true,
+	"secret_key":    true,
+	"session_token": true,
+}
+
+// Built-in mounts every Vault has; counted but not audited.
+var systemMountTypes = map[string]bool{
+	"system":       true,
+	"identity":     true,
+	"cubbyhole":    true,
+	"ns_system":    true,
+	"ns_identity":  true,
+	"ns_cubbyhole": true,
+	"token":        true,
+	"ns_token":     true,
+}
+
+type mountConfig struct {
+	DefaultLeaseTTL           int64    `json:"default_lease_ttl"`
+	MaxLeaseTTL               int64    `json:"max_lease_ttl"`
+	ListingVisibility         string   `json:"listing_visibility"`
+	AuditNonHMACRequestKeys   []string `json:"audit_non_hmac_request_keys"`
+	AuditNonHMACResponseKeys  []string `json:"audit_non_hmac_response_keys"`
+	PassthroughRequestHeaders []string `json:"passthrough_request_headers"`
+	TokenType                 string   `json:"token_type"`
+}
+
+// Entry shape shared by /sys/mounts and /sys/auth
+type mountEntry struct {
+	Type                 string         `json:"type"`
+	Accessor             string         `json:"accessor"`
+	Description          string         `json:"description"`
+	Local                bool           `json:"local"`
+	SealWrap             bool           `json:"seal_wrap"`
+	Options              map[string]any `json:"options"`
+	Config               mountConfig    `json:"config"`
+	RunningPluginVersion string         `json:"running_plugin_version"`
+	DeprecationStatus    string         `json:"deprecation_status"`
+}
+
+type mountsResp struct {
+	Data map[string]mountEntry `json:"data"`
+}
+
+func (m mountEntry) kvVersion() string {
+	if m.Type != "kv" && m.Type != "generic" {
+		return ""
+	}
+	if m.Options != nil {
+		if verRaw, ok := m.Options["version"]; ok && fmt.Sprintf("%v", verRaw) == "2" {
+			return "2"
+		}
+	}
+	return "1"
+}
+
+func ttlOrDefault(sec int64) string {
+	if sec == 0 {
+		return "default"
+	}
+	return humanTTL(sec)
+}
+
+// sensitiveKeys returns the keys that match sensitiveAuditKeys.
+func sensitiveKeys(keys []string) []string {
+	out := []string{}
+	for _, k := range keys {
+		if sensitiveAuditKeys[strings.ToLower(strings.TrimSpace(k))] {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// auditMountConfig describes a mount's tuning and returns any risky findings.
+func auditMountConfig(m mountEntry) (string, []string) {
+	parts := []string{"type=" + m.Type}
+	if v := m.kvVersion(); v != "" {
+		parts[0] += " v" + v
+	}
+	if m.RunningPluginVersion != "" {
+		parts = append(parts, "plugin="+m.RunningPluginVersion)
+	}
+	parts = append(parts, fmt.Sprintf("ttl=%s/%s", ttlOrDefault(m.Config.DefaultLeaseTTL), ttlOrDefault(m.Config.MaxLeaseTTL)))
+	if m.Local {
+		parts = append(parts, "local")
+	}
+	if m.SealWrap {
+		parts = append(parts, "seal_wrap")
+	}
+	if m.Config.ListingVisibility != "" {
+		parts = append(parts, "listing="+m.Config.ListingVisibility)
+	}
+	if len(m.Config.AuditNonHMACRequestKeys) > 0 {
+		parts = append(parts, "non_hmac_req="+strings.Join(m.Config.AuditNonHMACRequestKeys, ","))
+	}
+	if len(m.Config.AuditNonHMACResponseKeys) > 0 {
+		parts = append(parts, "non_hmac_resp="+strings.Join(m.Config.AuditNonHMACResponseKeys, ","))
+	}
+
+	findings := []string{}
+	if maxTTL := time.Duration(m.Config.MaxLeaseTTL) * time.Second; maxTTL > mountMaxTTLWarn {
+		findings = append(findings, fmt.Sprintf("max_lease_ttl %s exceeds %s", humanTTL(m.Config.MaxLeaseTTL), humanTTL(int64(mountMaxTTLWarn/time.Second))))
+	}
+	if keys := sensitiveKeys(m.Config.AuditNonHMACRequestKeys); len(keys) > 0 {
+		findings = append(findings, "sensitive request keys not HMAC'd: "+strings.Join(keys, ","))
+	}
+	if keys := sensitiveKeys(m.Config.AuditNonHMACResponseKeys); len(keys) > 0 {
+		findings = append(findings, "sensitive response keys not HMAC'd: "+strings.Join(keys, ","))
+	}
+	if m.kvVersion() == "1" {
+		findings = append(findings, "KV v1: no versioning or soft delete")
+	}
+	return strings.Join(parts, " "), findings
+}
+
+// Secret engines + KV flavors + per-mount tuning audit + KV v2 config.
+// Returns the diagnostics and the number of mounts.
+func mountDiagnostics(client *http.Client, cfg Config, opt Options) ([]check, int) {
+	diagnostics := []check{}
+	var m mountsResp
+	total := 0
+	if code, err := doGET(client, cfg, "/v1/sys/mounts", &m); err == nil && (code == 200 || code == 204) {
+		kvTotal := 0
+		kvV2 := 0
+		paths := make([]string, 0, len(m.Data))
+		for path, mount := range m.Data {
+			if path == "" {
+				continue
+			}
+			total++
+			seenMounts = append(seenMounts, path)
+			switch mount.kvVersion() {
+			case "2":
+				kvTotal++
+				kvV2++
+			case "1":
+				kvTotal++
+			}
+			paths = append(paths, path)
+		}
+		diagnostics = append(diagnostics, check{"mount.secret_engines", "Secret engines", true, fmt.Sprintf("%d", total), map[string]any{"count": total}})
+		kvV1 := kvTotal - kvV2
+		diagnostics = append(diagnostics, check{"mount.kv_engines", "KV engines", true, fmt.Sprintf("total=%d (v2=%d, v1=%d)", kvTotal, kvV2, kvV1),
+			map[string]any{"total": kvTotal, "v1": kvV1, "v2": kvV2}})
+
+		sort.Strings(paths)
+		for _, path := range paths {
+			mount := m.Data[path]
+			if systemMountTypes[mount.Type] {
+				continue
+			}
+			detail, findings := auditMountConfig(mount)
+			if len(findings) > 0 {
+				detail += " — " + strings.Join(findings, "; ")
+			}
+			diagnostics = append(diagnostics, itemCheck("mount.entry", path, "Mount "+path, len(findings) == 0, detail))
+			if mount.kvVersion() == "1" {
+				extraHints = append(extraHints, fmt.Sprintf("KV v1 mount %s: upgrade with 'vault kv enable-versioning %s' (clients must switch to the v2 data/ API).", path, path))
+			}
+			if mount.kvVersion() == "2" {
+				diagnostics = append(diagnostics, kvConfigDiagnostics(client, cfg, path, opt)...)
+			}
+		}
+	} else if code == 403 {
+		diagnostics = append(diagnostics, check{"mount.secret_engines", "Secret engines", true, "forbidden (insufficient perms)", nil})
+	}
+	return diagnostics, total
+}
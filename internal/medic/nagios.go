@@ -0,0 +1,312 @@
+package medic
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Nagios/Icinga plugin states, which are also the exit codes.
+const (
+	nagiosOK = iota
+	nagiosWarning
+	nagiosCritical
+	nagiosUnknown
+)
+
+var nagiosStates = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// Problems named on the status line; the rest are only counted.
+const nagiosProblemsShown = 3
+
+// Results that mean the plugin could not check at all (UNKNOWN, not CRITICAL).
+var nagiosUnknownIDs = map[string]bool{
+	"env.vault_addr":     true,
+	"auth.configuration": true,
+	"auth.approle_login": true,
+}
+
+// A threshold range in the plugin guidelines' syntax: "10" alerts outside
+// 0..10, "10:" below 10, "~:10" above 10, "10:20" outside, "@10:20" inside.
+type nagiosRange struct {
+	raw    string
+	lo, hi float64
+	inside bool
+}
+
+func parseNagiosRange(s string) (*nagiosRange, error) {
+	r := &nagiosRange{raw: s, lo: 0, hi: math.Inf(1)}
+	if strings.HasPrefix(s, "@") {
+		r.inside = true
+		s = s[1:]
+	}
+	lo, hi, hasColon := strings.Cut(s, ":")
+	if !hasColon {
+		lo, hi = "0", s
+	}
+	var err error
+	switch lo {
+	case "~":
+		r.lo = math.Inf(-1)
+	case "":
+	default:
+		if r.lo, err = strconv.ParseFloat(lo, 64); err != nil {
+			return nil, fmt.Errorf("bad threshold %q", r.raw)
+		}
+	}
+	if hi != "" {
+		if r.hi, err = strconv.ParseFloat(hi, 64); err != nil {
+			return nil, fmt.Errorf("bad threshold %q", r.raw)
+		}
+	}
+	if r.lo > r.hi {
+		return nil, fmt.Errorf("bad threshold %q: start > end", r.raw)
+	}
+	return r, nil
+}
+
+func (r *nagiosRange) alert(v float64) bool {
+	if r == nil {
+		return false
+	}
+	in := v >= r.lo && v <= r.hi
+	return in == r.inside
+}
+
+func (r *nagiosRange) String() string {
+	if r == nil {
+		return ""
+	}
+	return r.raw
+}
+
+type nagiosLimit struct {
+	warn, crit *nagiosRange
+}
+
+// Perfdata metrics and their units; defaults apply unless --warn/--crit
+// override them ("metric=" clears one).
+var nagiosMetrics = map[string]struct {
+	unit       string
+	warn, crit string
+}{
+	"latency":       {"ms", "250", "1000"},
+	"token_ttl":     {"s", "86400:", "3600:"},
+	"seal_progress": {"", "", ""},
+}
+
+// thresholds for --format nagios, parsed up front by Run
+var nagiosLimits map[string]nagiosLimit
+
+// parseNagiosThresholds applies "metric=range" specs over the defaults.
+func parseNagiosThresholds(warn, crit []string) (map[string]nagiosLimit, error) {
+	out := map[string]nagiosLimit{}
+	for name, m := range nagiosMetrics {
+		l := nagiosLimit{}
+		if m.warn != "" {
+			l.warn, _ = parseNagiosRange(m.warn)
+		}
+		if m.crit != "" {
+			l.crit, _ = parseNagiosRange(m.crit)
+		}
+		out[name] = l
+	}
+	apply := func(specs []string, critical bool) error {
+		for _, spec := range specs {
+			name, val, ok := strings.Cut(spec, "=")
+			name = strings.TrimSpace(name)
+			if _, known := nagiosMetrics[name]; !ok || !known {
+				return fmt.Errorf("bad threshold %q (use METRIC=RANGE; metrics: latency, token_ttl, seal_progress)", spec)
+			}
+			var r *nagiosRange
+			if val = strings.TrimSpace(val); val != "" {
+				var err error
+				if r, err = parseNagiosRange(val); err != nil {
+					return err
+				}
+			}
+			l := out[name]
+			if critical {
+				l.crit = r
+			} else {
+				l.warn = r
+			}
+			out[name] = l
+		}
+		return nil
+	}
+	if err := apply(warn, false); err != nil {
+		return nil, err
+	}
+	if err := apply(crit, true); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func numeric(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func findCheck(cs []jsonCheck, id string) *jsonCheck {
+	for i := range cs {
+		if cs[i].ID == id {
+			return &cs[i]
+		}
+	}
+	return nil
+}
+
+type nagiosPerf struct {
+	name  string
+	value float64
+	max   string
+}
+
+// nagiosPerfdata pulls the metrics out of the report; a metric is left out
+// when the run could not measure it (or the token never expires).
+func nagiosPerfdata(r jsonResult) []nagiosPerf {
+	perf := []nagiosPerf{}
+	if c := findCheck(r.Diagnostics, "health.latency"); c != nil {
+		if v, ok := numeric(c.Data["ms"]); ok {
+			perf = append(perf, nagiosPerf{"latency", v, ""})
+		}
+	} else {
+		// no echo_duration_ms (older Vault): use the traced health call
+	timings:
+		for _, t := range r.Timings {
+			for _, call := range t.Calls {
+				if strings.HasPrefix(call.Path, "/v1/sys/health") && call.Status != 0 {
+					perf = append(perf, nagiosPerf{"latency", call.TotalMS, ""})
+					break timings
+				}
+			}
+		}
+	}
+	if c := findCheck(r.Diagnostics, "token.ttl"); c != nil {
+		if v, ok := numeric(c.Data["ttl_seconds"]); ok && v > 0 {
+			perf = append(perf, nagiosPerf{"token_ttl", v, ""})
+		}
+	}
+	if r.SealProgress != nil {
+		max := ""
+		if c := findCheck(r.Diagnostics, "seal.type"); c != nil {
+			if v, ok := numeric(c.Data["threshold"]); ok {
+				max = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		perf = append(perf, nagiosPerf{"seal_progress", float64(*r.SealProgress), max})
+	}
+	return perf
+}
+
+// nagiosProblems is the status line's problem list: the count and the
+// first few names.
+func nagiosProblems(problems []string) string {
+	if len(problems) <= nagiosProblemsShown {
+		return fmt.Sprintf("%d problem(s): %s", len(problems), strings.Join(problems, "; "))
+	}
+	return fmt.Sprintf("%d problem(s): %s (+%d more)", len(problems),
+		strings.Join(problems[:nagiosProblemsShown], "; "), len(problems)-nagiosProblemsShown)
+}
+
+// renderNagios prints the plugin status line, long output and perfdata,
+// and returns the plugin exit code. Only the health, token TTL and seal
+// thresholds raise WARNING unless warnDiags is set; warning diagnostics and
+// telemetry are otherwise just counted.
+func renderNagios(w io.Writer, r jsonResult, warnDiags bool) int {
+	state := nagiosOK
+	raise := func(s int) {
+		if s > state {
+			state = s
+		}
+	}
+	problems := []string{}
+	long := []string{}
+
+	unknown := false
+	for _, c := range r.Checks {
+		if c.OK {
+			continue
+		}
+		if nagiosUnknownIDs[c.ID] {
+			unknown = true
+		}
+		raise(nagiosCritical)
+		problems = append(problems, c.Name)
+		long = append(long, fmt.Sprintf("CRITICAL: %s: %s", c.Name, c.Detail))
+	}
+
+	perf := nagiosPerfdata(r)
+	perfOut := make([]string, 0, len(perf))
+	for _, p := range perf {
+		l := nagiosLimits[p.name]
+		unit := nagiosMetrics[p.name].unit
+		val := strconv.FormatFloat(p.value, 'f', -1, 64)
+		switch {
+		case l.crit.alert(p.value):
+			raise(nagiosCritical)
+			problems = append(problems, fmt.Sprintf("%s %s%s (crit %s)", p.name, val, unit, l.crit))
+		case l.warn.alert(p.value):
+			raise(nagiosWarning)
+			problems = append(problems, fmt.Sprintf("%s %s%s (warn %s)", p.name, val, unit, l.warn))
+		}
+		perfOut = append(perfOut, strings.TrimRight(fmt.Sprintf("%s=%s%s;%s;%s;0;%s", p.name, val, unit, l.warn, l.crit, p.max), ";"))
+	}
+
+	warnings := 0
+	for _, d := range append(append([]jsonCheck{}, r.Diagnostics...), r.Telemetry...) {
+		if d.Severity != sevWarning {
+			continue
+		}
+		warnings++
+		if warnDiags {
+			raise(nagiosWarning)
+			problems = append(problems, d.Name)
+			long = append(long, fmt.Sprintf("WARNING: %s: %s", d.Name, d.Detail))
+		}
+	}
+	perfOut = append(perfOut, fmt.Sprintf("failures=%d;;;0", r.Failures), fmt.Sprintf("warnings=%d;;;0", warnings))
+
+	if unknown {
+		state = nagiosUnknown
+	}
+
+	summary := []string{}
+	if r.Mode != "" && r.Mode != "unknown" {
+		summary = append(summary, r.Mode)
+	}
+	if c := findCheck(r.Checks, "health.sealed"); c != nil {
+		if c.OK {
+			summary = append(summary, "unsealed")
+		} else if r.SealProgress != nil {
+			summary = append(summary, fmt.Sprintf("unseal progress %d/%s", *r.SealProgress, strings.Split(r.SealThreshold, "/")[0]))
+		}
+	}
+	text := strings.Join(summary, ", ")
+	if len(problems) > 0 {
+		if text != "" {
+			text += " - "
+		}
+		text += nagiosProblems(problems)
+	}
+	if text == "" {
+		text = "no status"
+	}
+
+	fmt.Fprintf(w, "VAULT %s - %s | %s\n", nagiosStates[state], text, strings.Join(perfOut, " "))
+	for _, l := range long {
+		fmt.Fprintln(w, l)
+	}
+	return state
+}
@@ -0,0 +1,79 @@
+package medic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNagiosRange(t *testing.T) {
+	tests := []struct {
+		spec  string
+		value float64
+		alert bool
+	}{
+		// "10": outside 0..10
+		{"10", -1, true},
+		{"10", 0, false},
+		{"10", 10, false},
+		{"10", 11, true},
+		// "10:": below 10
+		{"10:", 9, true},
+		{"10:", 10, false},
+		{"10:", 1e9, false},
+		// "~:10": above 10
+		{"~:10", -1e9, false},
+		{"~:10", 10, false},
+		{"~:10", 11, true},
+		// "@10:20": inside 10..20
+		{"@10:20", 9, false},
+		{"@10:20", 10, true},
+		{"@10:20", 20, true},
+		{"@10:20", 21, false},
+	}
+	for _, tt := range tests {
+		r, err := parseNagiosRange(tt.spec)
+		if err != nil {
+			t.Fatalf("parseNagiosRange(%q): %v", tt.spec, err)
+		}
+		if got := r.alert(tt.value); got != tt.alert {
+			t.Errorf("%q.alert(%v) = %v; want %v", tt.spec, tt.value, got, tt.alert)
+		}
+	}
+
+	for _, bad := range []string{"x", "20:10", "@a:b"} {
+		if _, err := parseNagiosRange(bad); err == nil {
+			t.Errorf("parseNagiosRange(%q): want error", bad)
+		}
+	}
+	var unset *nagiosRange
+	if unset.alert(1) {
+		t.Error("nil range must never alert")
+	}
+}
+
+func TestRenderNagiosWarnings(t *testing.T) {
+	diags := []jsonCheck{
+		{ID: "mount.entry", Name: "Mount a/", Severity: sevWarning},
+		{ID: "mount.entry", Name: "Mount b/", Severity: sevWarning},
+		{ID: "mount.entry", Name: "Mount c/", Severity: sevWarning},
+		{ID: "mount.entry", Name: "Mount d/", Severity: sevWarning},
+	}
+	r := jsonResult{Mode: "active", Diagnostics: diags}
+
+	var b strings.Builder
+	if got := renderNagios(&b, r, false); got != nagiosOK {
+		t.Errorf("warning diagnostics without the switch: state %d; want OK\n%s", got, b.String())
+	}
+	if !strings.Contains(b.String(), "warnings=4") {
+		t.Errorf("warnings not counted in perfdata:\n%s", b.String())
+	}
+
+	b.Reset()
+	if got := renderNagios(&b, r, true); got != nagiosWarning {
+		t.Errorf("warning diagnostics with the switch: state %d; want WARNING", got)
+	}
+	status, _, _ := strings.Cut(b.String(), " | ")
+	if want := "VAULT WARNING - active - 4 problem(s): Mount a/; Mount b/; Mount c/ (+1 more)"; status != want {
+		t.Errorf("status line %q; want %q", status, want)
+	}
+}
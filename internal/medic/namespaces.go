@@ -0,0 +1,200 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const nsWalkLimit = 1000 // max namespaces visited by --recursive-namespaces
+
+type nsCounts struct {
+	Mounts      int `json:"mounts"`
+	AuthMethods int `json:"auth_methods"`
+	Policies    int `json:"policies"`
+	Quotas      int `json:"quotas"`
+}
+
+func (c *nsCounts) add(o nsCounts) {
+	c.Mounts += o.Mounts
+	c.AuthMethods += o.AuthMethods
+	c.Policies += o.Policies
+	c.Quotas += o.Quotas
+}
+
+func (c nsCounts) String() string {
+	return fmt.Sprintf("mounts=%d auth=%d policies=%d quotas=%d", c.Mounts, c.AuthMethods, c.Policies, c.Quotas)
+}
+
+type namespaceReport struct {
+	path   string
+	counts nsCounts
+	diags  []check
+}
+
+// For JSON mode
+type jsonNamespace struct {
+	Path        string     `json:"path"`
+	Counts      nsCounts   `json:"counts"`
+	Flagged     int        `json:"flagged"`
+	Diagnostics []jsonDiag `json:"diagnostics,omitempty"`
+}
+
+// namespacePaths lists the direct child namespaces of cfg.Namespace
+// (nil on OSS or when listing is not permitted).
+func namespacePaths(client *http.Client, cfg Config) []string {
+	var lr listResp
+	if code, err := doLIST(client, cfg, "/v1/sys/namespaces", &lr); err != nil || code != 200 {
+		return nil
+	}
+	return lr.Data.Keys
+}
+
+func joinNamespace(parent, child string) string {
+	parent = strings.Trim(parent, "/")
+	child = strings.Trim(child, "/")
+	if parent == "" {
+		return child
+	}
+	return parent + "/" + child
+}
+
+// ACL policies. Returns the diagnostics and the number of policies.
+func policyDiagnostics(client *http.Client, cfg Config) ([]check, int) {
+	diagnostics := []check{}
+	var lr listResp
+	cnt := 0
+	if code, err := doLIST(client, cfg, "/v1/sys/policies/acl", &lr); err == nil && code == 200 {
+		cnt = len(lr.Data.Keys)
+		diagnostics = append(diagnostics, check{"policy.acl_count", "ACL policies", true, fmt.Sprintf("%d", cnt), map[string]any{"count": cnt}})
+	} else if code == 403 {
+		diagnostics = append(diagnostics, check{"policy.acl_count", "ACL policies", true, "forbidden (insufficient perms)", nil})
+	}
+	return diagnostics, cnt
+}
+
+// namespaceDiagnostics runs every namespace-scoped check against cfg.Namespace,
+// timing each part within st (nil when the rows are not in the report).
+func namespaceDiagnostics(client *http.Client, cfg Config, leases map[string]int, opt Options, st *stepTimer) namespaceReport {
+	r := namespaceReport{path: nsLabel(cfg.Namespace)}
+
+	done := st.part()
+	d, n := mountDiagnostics(client, cfg, opt)
+	done(d)
+	r.diags = append(r.diags, d...)
+	r.counts.Mounts = n
+
+	done = st.part()
+	d, n = authDiagnostics(client, cfg)
+	done(d)
+	r.diags = append(r.diags, d...)
+	r.counts.AuthMethods = n
+
+	done = st.part()
+	d, n = policyDiagnostics(client, cfg)
+	done(d)
+	r.diags = append(r.diags, d...)
+	r.counts.Policies = n
+
+	done = st.part()
+	d, n = quotaDiagnostics(client, cfg, leases)
+	done(d)
+	r.diags = append(r.diags, d...)
+	r.counts.Quotas = n
+
+	return r
+}
+
+// runNamespaceWalk visits every namespace below cfg.Namespace (breadth-first)
+// and fills nsReports, starting with the already computed current namespace.
+func runNamespaceWalk(client *http.Client, cfg Config, current namespaceReport, opt Options) {
+	nsReports = []namespaceReport{current}
+
+	queue := []string{}
+	for _, k := range namespacePaths(client, cfg) {
+		queue = append(queue, joinNamespace(cfg.Namespace, k))
+	}
+	visited := 0
+	for len(queue) > 0 && visited < nsWalkLimit {
+		ns := queue[0]
+		queue = queue[1:]
+		visited++
+
+		child := cfg
+		child.Namespace = ns
+		before := len(extraHints)
+		r := namespaceDiagnostics(client, child, nil, opt, nil)
+		// scope hints raised inside this namespace
+		for i := before; i < len(extraHints); i++ {
+			extraHints[i] = fmt.Sprintf("[%s] %s", r.path, extraHints[i])
+		}
+		nsReports = append(nsReports, r)
+
+		for _, k := range namespacePaths(client, child) {
+			queue = append(queue, joinNamespace(ns, k))
+		}
+	}
+	if len(queue) > 0 {
+		extraHints = append(extraHints, fmt.Sprintf("Namespace walk stopped after %d namespaces; %d not visited.", nsWalkLimit, len(queue)))
+	}
+
+	children := nsReports[1:]
+	sort.SliceStable(children, func(i, j int) bool { return children[i].path < children[j].path })
+}
+
+func namespaceTotals(reports []namespaceReport) nsCounts {
+	var t nsCounts
+	for _, r := range reports {
+		t.add(r.counts)
+	}
+	return t
+}
+
+func jsonNamespaces(reports []namespaceReport) []jsonNamespace {
+	out := make([]jsonNamespace, 0, len(reports))
+	for _, r := range reports {
+		jn := jsonNamespace{Path: r.path, Counts: r.counts}
+		for _, d := range r.diags {
+			if !d.ok {
+				jn.Flagged++
+			}
+			jn.Diagnostics = append(jn.Diagnostics, toJSONCheck(d, sevWarning))
+		}
+		out = append(out, jn)
+	}
+	return out
+}
+
+// printNamespaces shows counts per namespace plus any flagged diagnostics;
+// the full per-namespace detail is in the JSON output.
+func printNamespaces(reports []namespaceReport, opt Options) {
+	if opt.Quiet || opt.structured() || len(reports) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println(cwrap(fmt.Sprintf("Namespaces (%d)", len(reports)), colYellow, opt))
+
+	rows := make([]check, 0, len(reports)+1)
+	for _, r := range reports {
+		rows = append(rows, check{name: r.path})
+	}
+	rows = append(rows, check{name: "Total"})
+	nameW := nameColWidth(rows)
+
+	pad := func(s string) string {
+		if len(s) < nameW {
+			return s + strings.Repeat(" ", nameW-len(s))
+		}
+		return s
+	}
+	for _, r := range reports {
+		fmt.Printf("%s %s  %s\n", cwrap("•", colGreen, opt), pad(r.path), r.counts)
+		for _, d := range r.diags {
+			if !d.ok {
+				fmt.Printf("    %s %s  %s\n", cwrap("!", colYellow, opt), d.name, d.detail)
+			}
+		}
+	}
+	fmt.Printf("%s %s  %s\n", cwrap("Σ", colGreen, opt), pad("Total"), namespaceTotals(reports))
+}
@@ -0,0 +1,308 @@
+package medic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NotifyPresets accepted by --notify-preset.
+var NotifyPresets = []string{"auto", "generic", "slack", "teams"}
+
+const notifyMaxItems = 10 // per list, so chat messages stay readable
+
+// Overall run status sent to the webhook and remembered for --notify-on-change.
+type notifyState struct {
+	Addr      string   `json:"vault_addr"`
+	Status    string   `json:"status"` // ok|warning|critical
+	Failed    []string `json:"failed"`
+	Warnings  []string `json:"warnings"` // check IDs, with the subject for per-item checks
+	UpdatedAt string   `json:"updated_at"`
+}
+
+type notifyCheck struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Payload for the generic preset.
+type notifyPayload struct {
+	Source      string        `json:"source"`
+	Status      string        `json:"status"`
+	Previous    string        `json:"previous_status,omitempty"`
+	Addr        string        `json:"vault_addr"`
+	ClusterName string        `json:"cluster_name,omitempty"`
+	Mode        string        `json:"mode,omitempty"`
+	Failures    int           `json:"failures"`
+	Warnings    int           `json:"warnings"`
+	Failed      []notifyCheck `json:"failed"`
+	Warning     []notifyCheck `json:"warning"`
+	Hints       []string      `json:"hints,omitempty"`
+	Timestamp   int64         `json:"timestamp"`
+}
+
+func notifySummary(r jsonResult, addr string) (notifyPayload, notifyState) {
+	p := notifyPayload{
+		Source:      "vault_doctor",
+		Addr:        addr,
+		ClusterName: r.ClusterName,
+		Mode:        r.Mode,
+		Failures:    r.Failures,
+		Failed:      []notifyCheck{},
+		Warning:     []notifyCheck{},
+		Hints:       r.Hints,
+		Timestamp:   r.Timestamp,
+	}
+	st := notifyState{Addr: addr, Failed: []string{}, Warnings: []string{}, UpdatedAt: time.Now().UTC().Format(time.RFC3339)}
+	for _, c := range r.Checks {
+		if !c.OK {
+			p.Failed = append(p.Failed, notifyCheck{c.ID, c.Name, c.Detail})
+			st.Failed = append(st.Failed, c.Name)
+		}
+	}
+	diags := append(append([]jsonCheck{}, r.Diagnostics...), r.Telemetry...)
+	keys := checkKeys(diags)
+	for i, d := range diags {
+		if d.Severity == sevWarning {
+			p.Warning = append(p.Warning, notifyCheck{d.ID, d.Name, d.Detail})
+			st.Warnings = append(st.Warnings, keys[i])
+		}
+	}
+	p.Warnings = len(p.Warning)
+	slices.Sort(st.Failed)
+	slices.Sort(st.Warnings)
+
+	switch {
+	case len(p.Failed) > 0:
+		p.Status = "critical"
+	case len(p.Warning) > 0:
+		p.Status = "warning"
+	default:
+		p.Status = "ok"
+	}
+	st.Status = p.Status
+	return p, st
+}
+
+// notifyStatePath is one file per Vault address and namespace.
+func notifyStatePath(cfg Config, opt Options) (string, error) {
+	if opt.NotifyState != "" {
+		return opt.NotifyState, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(cfg.Addr + "\x00" + cfg.Namespace))
+	return filepath.Join(dir, "vault_doctor", "notify-"+hex.EncodeToString(sum[:6])+".json"), nil
+}
+
+func loadNotifyState(path string) *notifyState {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var st notifyState
+	if json.Unmarshal(data, &st) != nil {
+		return nil
+	}
+	return &st
+}
+
+func saveNotifyState(path string, st notifyState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// notifyPreset resolves "auto" from the webhook host.
+func notifyPreset(webhook, preset string) string {
+	if preset != "" && preset != "auto" {
+		return preset
+	}
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return "generic"
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "hooks.slack.com":
+		return "slack"
+	case strings.HasSuffix(host, ".webhook.office.com"), strings.HasSuffix(host, ".logic.azure.com"):
+		return "teams"
+	}
+	return "generic"
+}
+
+// notifyLines renders up to notifyMaxItems checks as "name: detail".
+func notifyLines(cs []notifyCheck, bullet string) string {
+	lines := []string{}
+	for i, c := range cs {
+		if i == notifyMaxItems {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(cs)-i))
+			break
+		}
+		line := bullet + c.Name
+		if c.Detail != "" {
+			line += ": " + c.Detail
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate cuts s to at most n runes, marking the cut with "…".
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+func notifyTitle(p notifyPayload) string {
+	name := p.ClusterName
+	if name == "" {
+		name = p.Addr
+	}
+	t := fmt.Sprintf("Vault %s: %s", name, strings.ToUpper(p.Status))
+	if p.Previous != "" && p.Previous != p.Status {
+		t += " (was " + strings.ToUpper(p.Previous) + ")"
+	}
+	return t
+}
+
+func slackPayload(p notifyPayload) map[string]any {
+	// Slack rejects section text over 3000 characters and headers over 150
+	text := func(s string) map[string]any {
+		return map[string]any{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": truncate(s, 2900)}}
+	}
+	blocks := []any{
+		map[string]any{"type": "header", "text": map[string]any{"type": "plain_text", "text": truncate(notifyTitle(p), 150)}},
+		text(fmt.Sprintf("*Mode:* %s   *Failures:* %d   *Warnings:* %d\n%s", orNone(p.Mode), p.Failures, p.Warnings, p.Addr)),
+	}
+	if len(p.Failed) > 0 {
+		blocks = append(blocks, text("*Failed checks*\n"+notifyLines(p.Failed, "• ")))
+	}
+	if len(p.Warning) > 0 {
+		blocks = append(blocks, text("*Warnings*\n"+notifyLines(p.Warning, "• ")))
+	}
+	if len(p.Hints) > 0 {
+		blocks = append(blocks, text("*Next actions*\n"+notifyLines(hintChecks(p.Hints), "• ")))
+	}
+	return map[string]any{"text": notifyTitle(p), "blocks": blocks}
+}
+
+func teamsPayload(p notifyPayload) map[string]any {
+	colour := map[string]string{"ok": "2EB67D", "warning": "ECB22E", "critical": "E01E5A"}[p.Status]
+	sections := []any{map[string]any{
+		"activityTitle":    notifyTitle(p),
+		"activitySubtitle": p.Addr,
+		"facts": []any{
+			map[string]any{"name": "Mode", "value": orNone(p.Mode)},
+			map[string]any{"name": "Failures", "value": fmt.Sprint(p.Failures)},
+			map[string]any{"name": "Warnings", "value": fmt.Sprint(p.Warnings)},
+		},
+	}}
+	// MessageCard text is Markdown; a blank line between items keeps them apart
+	list := func(title string, cs []notifyCheck) {
+		if len(cs) > 0 {
+			sections = append(sections, map[string]any{"title": title, "text": strings.ReplaceAll(notifyLines(cs, "- "), "\n", "\n\n")})
+		}
+	}
+	list("Failed checks", p.Failed)
+	list("Warnings", p.Warning)
+	list("Next actions", hintChecks(p.Hints))
+	return map[string]any{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"themeColor": colour,
+		"summary":    notifyTitle(p),
+		"title":      notifyTitle(p),
+		"sections":   sections,
+	}
+}
+
+func hintChecks(hints []string) []notifyCheck {
+	out := make([]notifyCheck, 0, len(hints))
+	for _, h := range hints {
+		out = append(out, notifyCheck{Name: h})
+	}
+	return out
+}
+
+func postWebhook(webhook string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := NewRequestJSON(http.MethodPost, webhook, body)
+	if err != nil {
+		return err
+	}
+	// plain client: no Vault headers, no tracing
+	res, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook returned HTTP %d", res.StatusCode)
+	}
+	return nil
+}
+
+// notify posts the run summary to --notify-webhook. With --notify-on-change
+// it only posts when the status or the set of failed or warning checks
+// differs from the last run. Errors go to stderr and never change the exit code.
+func notify(r jsonResult, cfg Config, opt Options, rd *redactor) {
+	if opt.NotifyWebhook == "" {
+		return
+	}
+	registerSecret(opt.NotifyWebhook) // chat webhook URLs are credentials
+	p, st := notifySummary(r, rd.str(cfg.Addr))
+
+	statePath, err := notifyStatePath(cfg, opt)
+	if err != nil && opt.NotifyOnChange {
+		fmt.Fprintf(os.Stderr, "notify: state: %v\n", err)
+		return
+	}
+	if prev := loadNotifyState(statePath); prev != nil {
+		p.Previous = prev.Status
+		if opt.NotifyOnChange && prev.Status == st.Status &&
+			slices.Equal(prev.Failed, st.Failed) && slices.Equal(prev.Warnings, st.Warnings) {
+			return
+		}
+	}
+
+	var payload any = p
+	switch notifyPreset(opt.NotifyWebhook, opt.NotifyPreset) {
+	case "slack":
+		payload = slackPayload(p)
+	case "teams":
+		payload = teamsPayload(p)
+	}
+	if err := postWebhook(opt.NotifyWebhook, payload); err != nil {
+		// keep the old state so the next run retries
+		fmt.Fprintf(os.Stderr, "notify: %s\n", rd.str(err.Error()))
+		return
+	}
+	if statePath != "" {
+		if err := saveNotifyState(statePath, st); err != nil {
+			fmt.Fprintf(os.Stderr, "notify: state: %v\n", err)
+		}
+	}
+}
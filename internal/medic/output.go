@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 )
 
 func normVersion(v string) string {
@@ -18,7 +17,7 @@ func normVersion(v string) string {
 }
 
 func printBanner(version string, opt Options) {
-	if opt.Quiet || opt.JSON {
+	if opt.Quiet || opt.structured() {
 		return
 	}
 	fmt.Printf("%s %s  %s  %s\n",
@@ -31,13 +30,21 @@ func printBanner(version string, opt Options) {
 
 func summaryLine(failures int) string {
 	if failures == 0 {
-		return "Medic finished: all checks passed ✔"
+		return summaryText(failures) + " ✔"
 	}
-	return fmt.Sprintf("Medic finished: %d check(s) failed ❌", failures)
+	return summaryText(failures) + " ❌"
+}
+
+// summaryText is summaryLine without the glyph, for documents.
+func summaryText(failures int) string {
+	if failures == 0 {
+		return "Medic finished: all checks passed"
+	}
+	return fmt.Sprintf("Medic finished: %d check(s) failed", failures)
 }
 
 func printResultsPretty(results []check, status int, trailer string, opt Options) {
-	if opt.Quiet || opt.JSON {
+	if opt.Quiet || opt.structured() {
 		return
 	}
 	if status != 0 {
@@ -85,22 +92,48 @@ func printResultsPretty(results []check, status int, trailer string, opt Options
 }
 
 func printDiagnostics(diags []check, opt Options) {
-	if opt.Quiet || opt.JSON || len(diags) == 0 {
+	printSection("Diagnostics", diags, opt)
+}
+
+func printSection(title string, diags []check, opt Options) {
+	if opt.Quiet || opt.structured() || len(diags) == 0 {
 		return
 	}
 	fmt.Println()
-	fmt.Printf("%s%s%s\n", cwrap("Diagnostics", colYellow, opt), "", "")
+	fmt.Printf("%s%s%s\n", cwrap(title, colYellow, opt), "", "")
 	nameW := nameColWidth(diags)
 	for _, d := range diags {
 		name := d.name
 		if len(name) < nameW {
 			name = name + strings.Repeat(" ", nameW-len(name))
 		}
-		fmt.Printf("%s %s  %s\n", cwrap("•", colGreen, opt), name, d.detail)
+		mark := cwrap("•", colGreen, opt)
+		if !d.ok {
+			mark = cwrap("!", colYellow, opt)
+		}
+		fmt.Printf("%s %s  %s\n", mark, name, d.detail)
+	}
+}
+
+// structured reports the output is a rendered document (JSON, Markdown,
+// HTML, JUnit, user template) rather than the interactive terminal view:
+// no banner or prompts.
+func (o Options) structured() bool {
+	return o.JSON || (o.Format != "" && o.Format != "pretty")
+}
+
+// format resolves --format, honouring --json as an alias.
+func (o Options) format() string {
+	if o.JSON {
+		return "json"
 	}
+	if o.Format == "" {
+		return "pretty"
+	}
+	return o.Format
 }
 
-// JSON/Quiet finisher
+// JSON/Quiet/document finisher
 func finish(results []check, status int, health *healthResp, httpStatus int, cfg Config, diags []check, opt Options) int {
 	failures := 0
 	for _, r := range results {
@@ -108,51 +141,43 @@ func finish(results []check, status int, health *healthResp, httpStatus int, cfg
 			failures++
 		}
 	}
-	hints := collectHints(health, status)
-
-	if opt.JSON {
-		out := jsonResult{
-			Version:        opt.Version,
-			Timestamp:      time.Now().Unix(),
-			Mode:           healthMode(status),
-			HTTPStatus:     httpStatus,
-			ClusterName:    health.ClusterName,
-			LeaderAddress:  "", // filled in diagnostics
-			LeaderIsSelf:   nil,
-			SealType:       jsonSealType,
-			SealThreshold:  jsonSealThresh,
-			SealProgress:   jsonSealProg,
-			TokenTTL:       jsonTokenTTL,
-			TokenRenewable: jsonTokenRen,
-			TokenOrphan:    jsonTokenOrph,
-			Checks:         make([]jsonCheck, 0, len(results)),
-			Hints:          hints,
-			Failures:       failures,
-		}
-		for _, r := range results {
-			out.Checks = append(out.Checks, jsonCheck{Name: r.name, OK: r.ok, Detail: r.detail})
-		}
-		if len(diags) > 0 {
-			out.Diagnostics = make([]jsonDiag, 0, len(diags))
-			for _, d := range diags {
-				out.Diagnostics = append(out.Diagnostics, jsonDiag{Name: d.name, OK: d.ok, Detail: d.detail})
-				// opportunistically lift leader_addr/self if present
-				if d.name == "Leader address" && out.LeaderAddress == "" {
-					out.LeaderAddress = d.detail
-				}
-				if d.name == "Leader is self" && out.LeaderIsSelf == nil {
-					v := d.detail == "true"
-					out.LeaderIsSelf = &v
-				}
-			}
-		}
+	hints := append(collectHints(health, status), extraHints...)
+
+	// every output mode goes through the redactor; reports are built from
+	// the raw checks so IDs, data and timings still resolve by name
+	rd := newRedactor(cfg, health, opt)
+	lastRedactor = rd
+	report := func() jsonResult {
+		return rd.report(buildReport(results, status, health, httpStatus, diags, hints, failures, opt))
+	}
+
+	if opt.NotifyWebhook != "" {
+		notify(report(), cfg, opt, rd)
+	}
+
+	switch {
+	case opt.format() == "json":
 		enc := mustJSONEncoder()
-		_ = enc.Encode(out)
-	} else if opt.Quiet {
+		_ = enc.Encode(report())
+	case opt.format() == "markdown":
+		renderMarkdown(reportOut, report())
+	case opt.format() == "html":
+		renderHTML(reportOut, report())
+	case opt.format() == "junit":
+		renderJUnit(reportOut, report())
+	case opt.format() == "nagios":
+		return renderNagios(reportOut, report(), opt.NagiosWarnDiagnostics)
+	case opt.format() == "template":
+		if err := renderTemplate(reportOut, reportTemplate, report()); err != nil {
+			fmt.Fprintln(os.Stderr, rd.str(err.Error()))
+			return 2
+		}
+	case opt.Quiet:
 		if failures > 0 {
 			fmt.Println("medic: checks failed")
 		}
-	} else {
+	default:
+		results, diags, hints = rd.checks(results), rd.checks(diags), rd.strings(hints)
 		printResultsPretty(results, status, summaryLine(failures), opt)
 		if len(hints) > 0 {
 			fmt.Println()
@@ -164,6 +189,9 @@ func finish(results []check, status int, health *healthResp, httpStatus int, cfg
 		if len(diags) > 0 {
 			printDiagnostics(diags, opt)
 		}
+		printSection("Telemetry", rd.checks(telemetryChecks), opt)
+		printSection("Slowest checks", rd.checks(slowestSteps()), opt)
+		printNamespaces(rd.namespaces(nsReports), opt)
 	}
 
 	if failures > 0 {
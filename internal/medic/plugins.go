@@ -0,0 +1,114 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type pluginCatalogResp struct {
+	Data struct {
+		Detailed []struct {
+			Name              string `json:"name"`
+			Type              string `json:"type"`
+			Version           string `json:"version"`
+			Builtin           bool   `json:"builtin"`
+			SHA256            string `json:"sha256"`
+			DeprecationStatus string `json:"deprecation_status"`
+		} `json:"detailed"`
+	} `json:"data"`
+}
+
+type pluginPinsResp struct {
+	Data struct {
+		PinnedVersions []struct {
+			Name    string `json:"name"`
+			Type    string `json:"type"`
+			Version string `json:"version"`
+		} `json:"pinned_versions"`
+	} `json:"data"`
+}
+
+func shortSHA(s string) string {
+	if len(s) > 12 {
+		return s[:12] + "…"
+	}
+	return s
+}
+
+// deprecationHint explains what a builtin's deprecation status means for upgrades.
+func deprecationHint(kind, path, typ, status string) string {
+	switch status {
+	case "removed":
+		return fmt.Sprintf("%s %s uses builtin %q, which is removed and blocks unseal on newer versions. Migrate or disable it before upgrading.", kind, path, typ)
+	case "pending removal":
+		return fmt.Sprintf("%s %s uses builtin %q, pending removal: the next major upgrade fails unless VAULT_ALLOW_PENDING_REMOVAL_MOUNTS is set. Migrate it now.", kind, path, typ)
+	default:
+		return fmt.Sprintf("%s %s uses deprecated builtin %q; plan a migration before it reaches pending removal.", kind, path, typ)
+	}
+}
+
+// deprecatedMounts flags mounts backed by builtins that are no longer supported.
+func deprecatedMounts(client *http.Client, cfg Config, endpoint, kind, prefix string) []check {
+	diagnostics := []check{}
+	var m mountsResp
+	if code, err := doGET(client, cfg, endpoint, &m); err != nil || code != 200 {
+		return diagnostics
+	}
+	paths := make([]string, 0, len(m.Data))
+	for p := range m.Data {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		mount := m.Data[p]
+		status := strings.ToLower(strings.TrimSpace(mount.DeprecationStatus))
+		if status == "" || status == "supported" {
+			continue
+		}
+		diagnostics = append(diagnostics, itemCheck("plugin.upgrade_blocker", prefix+p, "Upgrade blocker "+prefix+p, false, fmt.Sprintf("%s builtin %s is %s", kind, mount.Type, status)))
+		extraHints = append(extraHints, deprecationHint(kind, prefix+p, mount.Type, status))
+	}
+	return diagnostics
+}
+
+func pluginDiagnostics(client *http.Client, cfg Config) []check {
+	diagnostics := []check{}
+
+	var pc pluginCatalogResp
+	if code, err := doGET(client, cfg, "/v1/sys/plugins/catalog", &pc); err == nil && code == 200 {
+		builtin, external := 0, 0
+		rows := []check{}
+		for _, p := range pc.Data.Detailed {
+			if p.Builtin {
+				builtin++
+				continue
+			}
+			external++
+			version := p.Version
+			if version == "" {
+				version = "unversioned"
+			}
+			rows = append(rows, itemCheck("plugin.entry", p.Type+"/"+p.Name, "Plugin "+p.Type+"/"+p.Name, true, fmt.Sprintf("version=%s sha256=%s", version, shortSHA(p.SHA256))))
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i].name < rows[j].name })
+		diagnostics = append(diagnostics, check{"plugin.catalog", "Plugin catalog", true, fmt.Sprintf("builtin=%d external=%d", builtin, external),
+			map[string]any{"builtin": builtin, "external": external}})
+		diagnostics = append(diagnostics, rows...)
+	} else if code == 403 {
+		diagnostics = append(diagnostics, check{"plugin.catalog", "Plugin catalog", true, "forbidden (insufficient perms)", nil})
+	}
+
+	var pins pluginPinsResp
+	if code, err := doGET(client, cfg, "/v1/sys/plugins/pins", &pins); err == nil && code == 200 {
+		for _, p := range pins.Data.PinnedVersions {
+			diagnostics = append(diagnostics, itemCheck("plugin.pin", p.Type+"/"+p.Name, "Plugin pin "+p.Type+"/"+p.Name, true, "version="+p.Version))
+		}
+	}
+
+	diagnostics = append(diagnostics, deprecatedMounts(client, cfg, "/v1/sys/mounts", "Secrets engine", "")...)
+	diagnostics = append(diagnostics, deprecatedMounts(client, cfg, "/v1/sys/auth", "Auth method", "auth/")...)
+
+	return diagnostics
+}
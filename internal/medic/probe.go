@@ -0,0 +1,123 @@
+package medic
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ProbeExpectations accepted by probe --expect.
+var ProbeExpectations = []string{"active", "standby", "perf-standby", "unsealed", "initialized"}
+
+type ProbeOptions struct {
+	Expect  string
+	Listen  string // serve probes over HTTP instead of exiting
+	Timeout time.Duration
+	Verbose bool
+}
+
+// probeMatch reports whether the node is in the expected role.
+func probeMatch(expect string, h *healthResp, status int) bool {
+	mode := healthMode(status)
+	switch expect {
+	case "active":
+		return mode == "active"
+	case "standby":
+		// perf standbys are standbys too; an HA-unhealthy one is not
+		return mode == "standby" || mode == "perf-standby"
+	case "perf-standby":
+		return mode == "perf-standby"
+	case "unsealed":
+		return h.Initialized && !h.Sealed
+	case "initialized":
+		return h.Initialized
+	}
+	return false
+}
+
+// probeOnce does a single sys/health call; detail explains the outcome.
+func probeOnce(client *http.Client, cfg Config, expect string) (bool, string) {
+	h, status, err := vaultHealth(client, cfg)
+	if err != nil {
+		return false, err.Error()
+	}
+	ok := probeMatch(expect, h, status)
+	detail := fmt.Sprintf("mode=%s initialized=%v sealed=%v (HTTP %d)", healthMode(status), h.Initialized, h.Sealed, status)
+	if !ok {
+		detail = "expected " + expect + ", got " + detail
+	}
+	return ok, detail
+}
+
+// Probe checks the node role once (exit 0 match, 1 mismatch or unreachable,
+// 2 config error) or, with Listen set, serves the check over HTTP.
+func Probe(po ProbeOptions) int {
+	loadDotEnvIfPresent(".env")
+	cfg := LoadConfigFromEnv()
+	if cfg.Addr == "" {
+		fmt.Fprintln(os.Stderr, "probe: VAULT_ADDR not set")
+		return 2
+	}
+	// plain client: a long-running listener must not collect traces
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.SkipVerify}},
+		Timeout:   po.Timeout,
+	}
+
+	if po.Listen != "" {
+		return probeServe(client, cfg, po)
+	}
+	ok, detail := probeOnce(client, cfg, po.Expect)
+	if !ok {
+		if po.Verbose {
+			fmt.Printf("fail: %s\n", detail)
+		}
+		return 1
+	}
+	if po.Verbose {
+		fmt.Printf("ok: %s\n", detail)
+	}
+	return 0
+}
+
+// probeServe answers 200/503 per request: "/", /healthz, /readyz and /livez
+// check --expect, "/<expectation>" (e.g. /active) checks that role.
+func probeServe(client *http.Client, cfg Config, po ProbeOptions) int {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		expect := strings.Trim(r.URL.Path, "/")
+		switch {
+		case expect == "" || expect == "healthz" || expect == "readyz" || expect == "livez":
+			expect = po.Expect
+		case !slices.Contains(ProbeExpectations, expect):
+			http.NotFound(w, r)
+			return
+		}
+		ok, detail := probeOnce(client, cfg, expect)
+		if po.Verbose {
+			fmt.Fprintf(os.Stderr, "probe %s %s: %v %s\n", r.Method, r.URL.Path, ok, detail)
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "fail: %s\n", detail)
+			return
+		}
+		fmt.Fprintf(w, "ok: %s\n", detail)
+	})
+
+	srv := &http.Server{Addr: po.Listen, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	if po.Verbose {
+		fmt.Fprintf(os.Stderr, "probe: listening on %s (expect %s)\n", po.Listen, po.Expect)
+	}
+	if err := srv.ListenAndServe(); err != nil {
+		fmt.Fprintf(os.Stderr, "probe: %v\n", err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,187 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const leaseQuotaWarnPct = 80 // lease-count quota usage before we flag it
+
+type quotaConfigResp struct {
+	Data struct {
+		EnableRateLimitAuditLogging    bool     `json:"enable_rate_limit_audit_logging"`
+		EnableRateLimitResponseHeaders bool     `json:"enable_rate_limit_response_headers"`
+		RateLimitExemptPaths           []string `json:"rate_limit_exempt_paths"`
+		AbsoluteRateLimitExemptPaths   []string `json:"absolute_rate_limit_exempt_paths"`
+	} `json:"data"`
+}
+
+type quotaResp struct {
+	Data struct {
+		Name          string  `json:"name"`
+		Path          string  `json:"path"`
+		Role          string  `json:"role"`
+		Type          string  `json:"type"`
+		Rate          float64 `json:"rate"`
+		Interval      int64   `json:"interval"`
+		BlockInterval int64   `json:"block_interval"`
+		MaxLeases     int     `json:"max_leases"`
+		Inheritable   bool    `json:"inheritable"`
+	} `json:"data"`
+}
+
+func quotaScope(path, role string) string {
+	s := "path=" + path
+	if path == "" {
+		s = "path=[global]"
+	}
+	if role != "" {
+		s += " role=" + role
+	}
+	return s
+}
+
+// quotaTargetExists reports whether a quota path still points at something:
+// a mount (or a path below one) in this namespace, or a child namespace.
+func quotaTargetExists(path string, mounts, namespaces []string) bool {
+	if path == "" {
+		return true
+	}
+	p := strings.TrimSuffix(path, "/") + "/"
+	for _, m := range mounts {
+		if strings.HasPrefix(p, m) {
+			return true
+		}
+	}
+	for _, ns := range namespaces {
+		if strings.HasPrefix(p, ns) {
+			return true
+		}
+	}
+	return false
+}
+
+// leaseUsage sums the inventoried leases of every mount under a quota path.
+// The bool is false when the path sits below mount level and cannot be attributed.
+func leaseUsage(path string, leases map[string]int) (int, bool) {
+	if path == "" {
+		total := 0
+		for _, n := range leases {
+			total += n
+		}
+		return total, leases != nil
+	}
+	// "secret" must not match "secret2/": compare whole path segments
+	path = strings.TrimSuffix(path, "/") + "/"
+	total, matched := 0, false
+	for m, n := range leases {
+		if strings.HasPrefix(strings.TrimSuffix(m, "/")+"/", path) {
+			total += n
+			matched = true
+		}
+	}
+	return total, matched
+}
+
+// Resource quotas. leases is the per-mount lease inventory used to estimate
+// lease-count quota usage (nil when unknown). Returns the diagnostics and the
+// number of quotas found.
+func quotaDiagnostics(client *http.Client, cfg Config, leases map[string]int) ([]check, int) {
+	diagnostics := []check{}
+	count := 0
+
+	var qc quotaConfigResp
+	code, err := doGET(client, cfg, "/v1/sys/quotas/config", &qc)
+	switch {
+	case err == nil && code == 200:
+		exempt := len(qc.Data.RateLimitExemptPaths) + len(qc.Data.AbsoluteRateLimitExemptPaths)
+		diagnostics = append(diagnostics, check{"quota.config", "Quota config", true,
+			fmt.Sprintf("audit_logging=%v, response_headers=%v, exempt_paths=%d",
+				qc.Data.EnableRateLimitAuditLogging, qc.Data.EnableRateLimitResponseHeaders, exempt), nil})
+	case code == 403:
+		diagnostics = append(diagnostics, check{"quota.config", "Quotas", true, "forbidden (insufficient perms)", nil})
+		return diagnostics, count
+	case code == 404:
+		return diagnostics, count
+	}
+
+	mounts := mountPrefixes(client, cfg)
+	namespaces := namespacePaths(client, cfg)
+
+	// Rate limit quotas
+	var rl listResp
+	globalRate := false
+	rlCode, rlErr := doLIST(client, cfg, "/v1/sys/quotas/rate-limit", &rl)
+	if rlErr == nil && rlCode == 200 {
+		sort.Strings(rl.Data.Keys)
+		count += len(rl.Data.Keys)
+		for _, name := range rl.Data.Keys {
+			var q quotaResp
+			if code, err := doGET(client, cfg, "/v1/sys/quotas/rate-limit/"+name, &q); err != nil || code != 200 {
+				continue
+			}
+			if q.Data.Path == "" && q.Data.Role == "" {
+				globalRate = true
+			}
+			detail := fmt.Sprintf("%s rate=%g/%s", quotaScope(q.Data.Path, q.Data.Role), q.Data.Rate, humanTTL(q.Data.Interval))
+			if q.Data.BlockInterval > 0 {
+				detail += " block=" + humanTTL(q.Data.BlockInterval)
+			}
+			ok := quotaTargetExists(q.Data.Path, mounts, namespaces)
+			if !ok {
+				detail += " — target mount not found"
+				extraHints = append(extraHints, fmt.Sprintf("Rate limit quota %q targets %s, which no longer exists; delete or retarget it.", name, q.Data.Path))
+			}
+			diagnostics = append(diagnostics, itemCheck("quota.rate_limit", name, "Rate limit "+name, ok, detail))
+		}
+	} else if rlCode == 403 {
+		diagnostics = append(diagnostics, check{"quota.rate_limits", "Rate limit quotas", true, "forbidden (insufficient perms)", nil})
+	}
+	// a path-less quota is only global in the root namespace; without a
+	// successful LIST we cannot tell whether one exists
+	listed := rlErr == nil && (rlCode == 200 || rlCode == 404)
+	if listed && !globalRate && strings.Trim(cfg.Namespace, "/") == "" {
+		diagnostics = append(diagnostics, check{"quota.global_rate_limit", "Global rate limit", false, "none configured", nil})
+		extraHints = append(extraHints, "No global rate limit quota. Add one (vault write sys/quotas/rate-limit/global rate=...) to protect Vault from runaway clients.")
+	}
+
+	// Lease count quotas
+	var lc listResp
+	if code, err := doLIST(client, cfg, "/v1/sys/quotas/lease-count", &lc); err == nil && code == 200 {
+		sort.Strings(lc.Data.Keys)
+		count += len(lc.Data.Keys)
+		for _, name := range lc.Data.Keys {
+			var q quotaResp
+			if code, err := doGET(client, cfg, "/v1/sys/quotas/lease-count/"+name, &q); err != nil || code != 200 {
+				continue
+			}
+			detail := fmt.Sprintf("%s max_leases=%d", quotaScope(q.Data.Path, q.Data.Role), q.Data.MaxLeases)
+			ok := true
+			if used, known := leaseUsage(q.Data.Path, leases); known && q.Data.MaxLeases > 0 {
+				pct := float64(used) * 100 / float64(q.Data.MaxLeases)
+				if leaseCountsPartial {
+					// the lease walk ran out of budget: usage is at least this
+					detail += fmt.Sprintf(" used≥%d (≥%.0f%%, lease walk truncated)", used, pct)
+				} else {
+					detail += fmt.Sprintf(" used=%d (%.0f%%)", used, pct)
+				}
+				if pct >= leaseQuotaWarnPct {
+					ok = false
+					extraHints = append(extraHints, fmt.Sprintf("Lease count quota %q is at %.0f%%; new leases on %s will be rejected at the limit.", name, pct, q.Data.Path))
+				}
+			}
+			if !quotaTargetExists(q.Data.Path, mounts, namespaces) {
+				ok = false
+				detail += " — target mount not found"
+				extraHints = append(extraHints, fmt.Sprintf("Lease count quota %q targets %s, which no longer exists; delete or retarget it.", name, q.Data.Path))
+			}
+			diagnostics = append(diagnostics, itemCheck("quota.lease_count", name, "Lease quota "+name, ok, detail))
+		}
+	} else if code == 403 {
+		diagnostics = append(diagnostics, check{"quota.lease_counts", "Lease count quotas", true, "forbidden (insufficient perms)", nil})
+	}
+
+	return diagnostics, count
+}
@@ -0,0 +1,26 @@
+package medic
+
+import "testing"
+
+func TestLeaseUsage(t *testing.T) {
+	leases := map[string]int{"secret/": 3, "secret2/": 5, "auth/approle/": 2}
+	tests := []struct {
+		path  string
+		used  int
+		known bool
+	}{
+		{"", 10, true},
+		{"secret", 3, true},
+		{"secret/", 3, true},
+		{"secret2/", 5, true},
+		{"auth/approle", 2, true},
+		{"auth/", 2, true},
+		{"gone/", 0, false},
+	}
+	for _, tt := range tests {
+		used, known := leaseUsage(tt.path, leases)
+		if used != tt.used || known != tt.known {
+			t.Errorf("leaseUsage(%q) = %d, %v; want %d, %v", tt.path, used, known, tt.used, tt.known)
+		}
+	}
+}
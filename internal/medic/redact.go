@@ -0,0 +1,329 @@
+package medic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Credentials never printed in any output mode, whether or not --anonymize
+// is set. Order matters: specific token formats before the generic forms.
+var secretPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`\bhv[sbr]\.[A-Za-z0-9_-]{20,}`), "[redacted-token]"},
+	{regexp.MustCompile(`\b[sbr]\.[A-Za-z0-9]{24}\b`), "[redacted-token]"},
+	{regexp.MustCompile(`(?i)\b([a-z_]*accessor|secret_id|client_token|token|unseal_key|recovery_key)(["']?\s*[:=]\s*["']?)[^\s"',;)]+`), "${1}${2}[redacted]"},
+	// mount accessors: auth_<type>_<hex8> or <type>_<hex8> for builtin engines
+	{regexp.MustCompile(`\b(?:auth_[a-z0-9-]+|(?:ns_)?(?:kv|generic|cubbyhole|system|identity|token|pki|transit|database|aws|azure|gcp|gcpkms|ssh|totp|rabbitmq|consul|nomad|ldap|openldap|ad|kubernetes|kmip|transform|keymgmt|terraform|alicloud|mongodbatlas))_[0-9a-f]{8}\b`), "[redacted-accessor]"},
+	{regexp.MustCompile(`[A-Za-z0-9+/]{43}=`), "[redacted-key]"}, // base64 unseal/recovery key
+}
+
+var (
+	urlHost = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)(\[[0-9a-fA-F:]+\]|[^/\s:"'\]]+)`)
+	ipv4    = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+)
+
+// Paths Vault mounts itself; not identifying, so never pseudonymised.
+var builtinMountPaths = map[string]bool{"sys/": true, "cubbyhole/": true, "identity/": true, "token/": true}
+
+// Cluster, host and namespace names too generic to identify anyone; swapping
+// them would only garble ordinary text ("vault kv", "prod" in "production").
+var commonNames = map[string]bool{
+	"vault": true, "cluster": true, "default": true, "root": true, "admin": true,
+	"prod": true, "production": true, "stage": true, "staging": true, "uat": true,
+	"qa": true, "test": true, "dev": true, "development": true, "demo": true,
+	"primary": true, "secondary": true, "dr": true, "main": true, "local": true,
+	"localhost": true, "shared": true, "internal": true, "public": true, "private": true,
+}
+
+// Characters that may not touch a learned term, so "prod" leaves
+// "production" and "vault_doctor" alone.
+const (
+	termBoundary = `[^A-Za-z0-9_.-]`
+	// Host names also end at "_" and ".": Vault prefixes metric names with
+	// the hostname (vault.<host>.runtime..., vault_<host>_runtime_...).
+	hostBoundary = `[^A-Za-z0-9-]`
+)
+
+// Prometheus turns the "-" and "." of a hostname into "_" in metric names.
+var promNameChars = strings.NewReplacer("-", "_", ".", "_")
+
+// identifying reports whether name is worth a pseudonym.
+func identifying(name string) bool {
+	return len(name) >= 3 && !commonNames[strings.ToLower(name)]
+}
+
+var (
+	// exact credential values seen this run (token, AppRole IDs, unseal keys)
+	knownSecrets []string
+
+	// mount paths seen by the mount/auth diagnostics, for --anonymize
+	seenMounts     []string
+	seenAuthMounts []string
+
+	// redactor of the last report, reused by bundle so pseudonyms match
+	lastRedactor *redactor
+)
+
+// registerSecret makes sure s is redacted from every output.
+func registerSecret(s string) {
+	if s = strings.TrimSpace(s); len(s) >= 6 {
+		knownSecrets = append(knownSecrets, s)
+	}
+}
+
+// redactor scrubs credentials and, with --anonymize, swaps identifying
+// names for pseudonyms that stay consistent within one report.
+type redactor struct {
+	anonymize bool
+	pseudo    map[string]string // kind + "\x00" + original -> pseudonym
+	counters  map[string]int
+	terms     map[string]string // learned names -> pseudonym
+	hosts     map[string]bool   // terms that are host names
+	termRe    *regexp.Regexp
+	hostRe    *regexp.Regexp
+}
+
+func newRedactor(cfg Config, health *healthResp, opt Options) *redactor {
+	r := &redactor{anonymize: opt.Anonymize, pseudo: map[string]string{}, counters: map[string]int{}, terms: map[string]string{}, hosts: map[string]bool{}}
+	registerSecret(cfg.Token)
+	registerSecret(cfg.SecretID)
+	registerSecret(cfg.RoleID)
+	if !r.anonymize {
+		return r
+	}
+
+	if health != nil && identifying(health.ClusterName) {
+		r.terms[health.ClusterName] = r.name("cluster", health.ClusterName)
+	}
+	if u, err := url.Parse(cfg.Addr); err == nil && u.Hostname() != "" {
+		r.host(u.Hostname())
+	}
+	if jsonHost != nil && identifying(jsonHost.Hostname) {
+		h, p := jsonHost.Hostname, r.host(jsonHost.Hostname)
+		r.terms[h], r.hosts[h] = p, true
+		r.terms[promNameChars.Replace(h)], r.hosts[promNameChars.Replace(h)] = promNameChars.Replace(p), true
+	}
+	for _, p := range seenMounts {
+		if !builtinMountPaths[p] {
+			r.terms[p] = r.name("mount", p) + "/"
+		}
+	}
+	for _, p := range seenAuthMounts {
+		if !builtinMountPaths[p] {
+			r.terms[p] = r.name("auth", p) + "/"
+		}
+	}
+	nss := []string{cfg.Namespace}
+	for _, n := range nsReports {
+		nss = append(nss, n.path)
+	}
+	if jsonClientCounts != nil {
+		for _, n := range jsonClientCounts.Namespaces {
+			nss = append(nss, n.Namespace)
+		}
+	}
+	for _, ns := range nss {
+		r.namespace(ns)
+	}
+
+	var terms, hosts []string
+	for t := range r.terms {
+		if r.hosts[t] {
+			hosts = append(hosts, t)
+		} else {
+			terms = append(terms, t)
+		}
+	}
+	r.termRe = termRegexp(terms, termBoundary)
+	r.hostRe = termRegexp(hosts, hostBoundary)
+	return r
+}
+
+// termRegexp matches any of terms between boundary characters, or nil when
+// there are none.
+func termRegexp(terms []string, boundary string) *regexp.Regexp {
+	if len(terms) == 0 {
+		return nil
+	}
+	// longest first, so "prod-kv/" wins over "kv/"
+	sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+		// a term ends at a boundary unless it ends in "/" ("kv/data/x")
+		if !strings.HasSuffix(t, "/") {
+			quoted[i] += `(?:` + boundary + `|$)`
+		}
+	}
+	return regexp.MustCompile(`(^|` + boundary + `)(` + strings.Join(quoted, "|") + `)`)
+}
+
+// name hands out kind-1, kind-2, ... in order of first sight.
+func (r *redactor) name(kind, original string) string {
+	key := kind + "\x00" + original
+	if p, ok := r.pseudo[key]; ok {
+		return p
+	}
+	r.counters[kind]++
+	p := fmt.Sprintf("%s-%d", kind, r.counters[kind])
+	r.pseudo[key] = p
+	return p
+}
+
+func (r *redactor) host(h string) string {
+	if net.ParseIP(strings.Trim(h, "[]")) != nil {
+		return r.name("ip", h)
+	}
+	return r.name("host", strings.ToLower(h))
+}
+
+// namespace maps each path segment separately, so "team-a/eu/" becomes
+// "ns-1/ns-2/" and "team-a/" stays "ns-1/". Generic segments such as "dev"
+// are kept, and a path made only of them is left alone.
+func (r *redactor) namespace(path string) {
+	path = strings.Trim(path, "/")
+	if path == "" || path == "[root]" {
+		return
+	}
+	parts := strings.Split(path, "/")
+	out := make([]string, len(parts))
+	changed := false
+	for i, p := range parts {
+		out[i] = p
+		if identifying(p) {
+			out[i] = r.name("ns", strings.Join(parts[:i+1], "/"))
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	r.terms[path+"/"] = strings.Join(out, "/") + "/"
+	r.terms[path] = strings.Join(out, "/")
+}
+
+func (r *redactor) str(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, k := range knownSecrets {
+		s = strings.ReplaceAll(s, k, "[redacted]")
+	}
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	if !r.anonymize {
+		return s
+	}
+	s = r.scrubTerms(s)
+	s = urlHost.ReplaceAllStringFunc(s, func(m string) string {
+		sub := urlHost.FindStringSubmatch(m)
+		return sub[1] + r.host(sub[2])
+	})
+	return ipv4.ReplaceAllStringFunc(s, func(m string) string {
+		if net.ParseIP(m) == nil {
+			return m
+		}
+		return r.name("ip", m)
+	})
+}
+
+// scrubTerms swaps learned names (mounts, namespaces, cluster and host
+// names) for their pseudonyms.
+func (r *redactor) scrubTerms(s string) string {
+	for _, re := range []*regexp.Regexp{r.termRe, r.hostRe} {
+		if re == nil {
+			continue
+		}
+		// a match may consume the boundary after it, so a term right after
+		// another one ("a b") is only found by a second pass
+		for range 2 {
+			s = re.ReplaceAllStringFunc(s, func(m string) string {
+				sub := re.FindStringSubmatch(m)
+				term, end := sub[2], ""
+				if _, ok := r.terms[term]; !ok {
+					_, n := utf8.DecodeLastRuneInString(term)
+					term, end = term[:len(term)-n], term[len(term)-n:]
+				}
+				return sub[1] + r.terms[term] + end
+			})
+		}
+	}
+	return s
+}
+
+func (r *redactor) strings(in []string) []string {
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = r.str(s)
+	}
+	return out
+}
+
+func (r *redactor) checks(in []check) []check {
+	if in == nil {
+		return nil
+	}
+	out := make([]check, len(in))
+	for i, c := range in {
+		out[i] = check{c.id, r.str(c.name), c.ok, r.str(c.detail), c.data} // data is scrubbed with the report
+	}
+	return out
+}
+
+func (r *redactor) namespaces(in []namespaceReport) []namespaceReport {
+	out := make([]namespaceReport, len(in))
+	for i, n := range in {
+		out[i] = namespaceReport{path: r.str(n.path), counts: n.counts, diags: r.checks(n.diags)}
+	}
+	return out
+}
+
+// report scrubs every string (and map key) in the assembled report, which
+// covers values lifted from globals such as host info and check data.
+func (r *redactor) report(in jsonResult) jsonResult {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return in
+	}
+	var tree any
+	if err := json.Unmarshal(b, &tree); err != nil {
+		return in
+	}
+	b, err = json.Marshal(r.walk(tree))
+	if err != nil {
+		return in
+	}
+	var out jsonResult
+	if err := json.Unmarshal(b, &out); err != nil {
+		return in
+	}
+	return out
+}
+
+func (r *redactor) walk(v any) any {
+	switch t := v.(type) {
+	case string:
+		return r.str(t)
+	case []any:
+		for i := range t {
+			t[i] = r.walk(t[i])
+		}
+		return t
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			out[r.str(k)] = r.walk(val)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,109 @@
+package medic
+
+import "testing"
+
+func TestSecretPatterns(t *testing.T) {
+	r := &redactor{}
+	tests := []struct {
+		in, want string
+	}{
+		{"token hvs.CAESIJx0aGlzaXNhdGVzdHRva2Vu", "token [redacted-token]"},
+		{"legacy s.abcdefghijklmnopqrstuvwx", "legacy [redacted-token]"},
+		{`"accessor": "auth_approle_1a2b3c4d"`, `"accessor": "[redacted]"`},
+		{"token_accessor=Xy12ab", "token_accessor=[redacted]"},
+		{"secret_id: 3f2504e0-4f89-11d3-9a0c-0305e82c3301", "secret_id: [redacted]"},
+		{"mount kv_1a2b3c4d", "mount [redacted-accessor]"},
+		{"via auth_userpass_0badf00d", "via [redacted-accessor]"},
+		{"unseal key 6o5KX2Hk2xqMRyU1nNdJ8oQ0dHBd4pQr8cQWJQj2m0U=", "unseal key [redacted-key]"},
+		// not secrets: quota names, request/namespace IDs, plugin digests
+		{"quota global_20240101", "quota global_20240101"},
+		{"request_id 3f2504e0-4f89-11d3-9a0c-0305e82c3301", "request_id 3f2504e0-4f89-11d3-9a0c-0305e82c3301"},
+		{"sha256=9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", "sha256=9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+		{"vault_token_count 12", "vault_token_count 12"},
+	}
+	for _, tt := range tests {
+		if got := r.str(tt.in); got != tt.want {
+			t.Errorf("str(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	seenMounts = []string{"prod-kv/", "kv/", "sys/"}
+	nsReports = []namespaceReport{{path: "team-a/"}, {path: "team-a/emea/"}, {path: "team-a/eu/"}, {path: "prod/"}}
+	t.Cleanup(func() { seenMounts, nsReports = nil, nil })
+
+	cfg := Config{Addr: "https://vault.example.com:8200"}
+	r := newRedactor(cfg, &healthResp{ClusterName: "acme-eu"}, Options{Anonymize: true})
+	tests := []struct {
+		in, want string
+	}{
+		{"cluster acme-eu is active", "cluster cluster-1 is active"},
+		{"https://vault.example.com:8200/v1/sys/health", "https://host-1:8200/v1/sys/health"},
+		{"prod-kv/ and kv/data/app", "mount-1/ and mount-2/data/app"},
+		{"sys/ stays", "sys/ stays"},
+		{"team-a/emea/ under team-a/", "ns-1/ns-2/ under ns-1/"},
+		{"team-a/eu/", "ns-1/eu/"},
+		{"team-a team-a", "ns-1 ns-1"},
+		// whole segments only
+		{"team-abc and acme-eu_x", "team-abc and acme-eu_x"},
+		// generic names are not identifying
+		{"prod/ in production, dev/", "prod/ in production, dev/"},
+	}
+	for _, tt := range tests {
+		if got := r.str(tt.in); got != tt.want {
+			t.Errorf("str(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+
+	common := newRedactor(cfg, &healthResp{ClusterName: "vault"}, Options{Anonymize: true})
+	for _, s := range []string{"vault_doctor", "vault kv get", "cluster vault"} {
+		if got := common.str(s); got != s {
+			t.Errorf("str(%q) = %q; want it unchanged", s, got)
+		}
+	}
+}
+
+func TestRedactMetrics(t *testing.T) {
+	r := &redactor{}
+	in := "# HELP vault_token_count Number of tokens\n" +
+		`vault_token_count{auth_method="approle",mount_point="auth/approle/"} 3f2504e0` + "\n" +
+		`vault_core_mount_table_size{local="false",path="kv_1a2b3c4d"} 1.2e+06` + "\n" +
+		"vault_runtime_num_goroutines 9a0c0305e82c3301\n"
+	want := "# HELP vault_token_count Number of tokens\n" +
+		`vault_token_count{auth_method="approle",mount_point="auth/approle/"} 3f2504e0` + "\n" +
+		`vault_core_mount_table_size{local="false",path="[redacted-accessor]"} 1.2e+06` + "\n" +
+		"vault_runtime_num_goroutines 9a0c0305e82c3301\n"
+	if got := string(r.redactMetrics([]byte(in))); got != want {
+		t.Errorf("redactMetrics:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRedactMetricsHostname(t *testing.T) {
+	jsonHost = &jsonHostInfo{Hostname: "vault-node-7"}
+	t.Cleanup(func() { jsonHost = nil })
+
+	r := newRedactor(Config{}, nil, Options{Anonymize: true})
+	in := "# TYPE vault_vault_node_7_runtime_alloc_bytes gauge\n" +
+		"vault_vault_node_7_runtime_alloc_bytes 1.2e+07\n" +
+		`vault_vault_node_7_runtime_gc_pause_ns{quantile="0.99"} 3000` + "\n" +
+		"vault_vault_node_70_runtime_alloc_bytes 5\n"
+	want := "# TYPE vault_host_1_runtime_alloc_bytes gauge\n" +
+		"vault_host_1_runtime_alloc_bytes 1.2e+07\n" +
+		`vault_host_1_runtime_gc_pause_ns{quantile="0.99"} 3000` + "\n" +
+		"vault_vault_node_70_runtime_alloc_bytes 5\n"
+	if got := string(r.redactMetrics([]byte(in))); got != want {
+		t.Errorf("redactMetrics:\n%s\nwant:\n%s", got, want)
+	}
+
+	// the JSON metrics keep the hostname as is, between dots
+	for in, want := range map[string]string{
+		"vault.vault-node-7.runtime.alloc_bytes": "vault.host-1.runtime.alloc_bytes",
+		"host vault-node-7 is up":                "host host-1 is up",
+		"vault-node-7x":                          "vault-node-7x",
+	} {
+		if got := r.str(in); got != want {
+			t.Errorf("str(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package medic
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// Formats accepted by --format.
+var Formats = []string{"pretty", "json", "markdown", "html", "junit", "template", "nagios"}
+
+// ValidFormat reports whether f is a known --format value.
+func ValidFormat(f string) bool {
+	for _, v := range Formats {
+		if f == v {
+			return true
+		}
+	}
+	return false
+}
+
+// buildReport assembles the medic result shared by the JSON, Markdown and
+// HTML renderers.
+func buildReport(results []check, status int, health *healthResp, httpStatus int, diags []check, hints []string, failures int, opt Options) jsonResult {
+	out := jsonResult{
+		SchemaVersion:  SchemaVersion,
+		Version:        opt.Version,
+		Timestamp:      time.Now().Unix(),
+		DurationMS:     msSince(runStarted),
+		Mode:           healthMode(status),
+		HTTPStatus:     httpStatus,
+		LeaderAddress:  "", // filled in diagnostics
+		LeaderIsSelf:   nil,
+		SealType:       jsonSealType,
+		SealThreshold:  jsonSealThresh,
+		SealProgress:   jsonSealProg,
+		StorageType:    jsonStorage,
+		HAEnabled:      jsonHAEnabled,
+		NodeCount:      jsonNodeCount,
+		TokenTTL:       jsonTokenTTL,
+		TokenRenewable: jsonTokenRen,
+		TokenOrphan:    jsonTokenOrph,
+		ClientCounts:   jsonClientCounts,
+		Host:           jsonHost,
+		Checks:         make([]jsonCheck, 0, len(results)),
+		Hints:          hints,
+		Failures:       failures,
+	}
+	if health != nil {
+		out.ClusterName = health.ClusterName
+	}
+	for _, r := range results {
+		out.Checks = append(out.Checks, toJSONCheck(r, sevCritical))
+	}
+	if len(diags) > 0 {
+		out.Diagnostics = make([]jsonDiag, 0, len(diags))
+		for _, d := range diags {
+			out.Diagnostics = append(out.Diagnostics, toJSONCheck(d, sevWarning))
+			// opportunistically lift leader_addr/self if present
+			if d.name == "Leader address" && out.LeaderAddress == "" {
+				out.LeaderAddress = d.detail
+			}
+			if d.name == "Leader is self" && out.LeaderIsSelf == nil {
+				v := d.detail == "true"
+				out.LeaderIsSelf = &v
+			}
+		}
+	}
+	for _, t := range telemetryChecks {
+		out.Telemetry = append(out.Telemetry, toJSONCheck(t, sevWarning))
+	}
+	stampSteps("checks", out.Checks)
+	stampSteps("diagnostics", out.Diagnostics)
+	stampSteps("telemetry", out.Telemetry)
+	out.Timings = stepTimings
+	if len(nsReports) > 0 {
+		out.Namespaces = jsonNamespaces(nsReports)
+		t := namespaceTotals(nsReports)
+		out.NamespaceTotals = &t
+	}
+	return out
+}
+
+// reportMeta is the cluster metadata block shown at the top of documents;
+// empty values are left out.
+func reportMeta(r jsonResult) [][2]string {
+	rows := [][2]string{}
+	add := func(k, v string) {
+		if strings.TrimSpace(v) != "" {
+			rows = append(rows, [2]string{k, v})
+		}
+	}
+	boolStr := func(b *bool) string {
+		if b == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v", *b)
+	}
+	add("Generated", time.Unix(r.Timestamp, 0).UTC().Format(time.RFC3339))
+	add("vault_doctor", normVersion(r.Version))
+	if r.HTTPStatus != 0 {
+		add("Mode", fmt.Sprintf("%s (HTTP %d)", r.Mode, r.HTTPStatus))
+	}
+	add("Cluster", r.ClusterName)
+	add("Leader", r.LeaderAddress)
+	add("Leader is self", boolStr(r.LeaderIsSelf))
+	seal := r.SealType
+	if r.SealThreshold != "" {
+		seal += " (threshold " + r.SealThreshold + ")"
+	}
+	add("Seal", seal)
+	add("Storage", r.StorageType)
+	add("HA enabled", boolStr(r.HAEnabled))
+	if r.NodeCount != nil {
+		add("Raft nodes", fmt.Sprintf("%d", *r.NodeCount))
+	}
+	add("Token TTL", r.TokenTTL)
+	return rows
+}
+
+// ---- Markdown ----
+
+// mdCell escapes a value for a Markdown table cell.
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+func mdStatus(ok, diag bool) string {
+	switch {
+	case ok:
+		return "OK"
+	case diag:
+		return "**WARN**"
+	}
+	return "**FAIL**"
+}
+
+func mdTable(w io.Writer, rows []jsonCheck, diag bool) {
+	fmt.Fprintln(w, "| Status | Check | Detail |")
+	fmt.Fprintln(w, "|---|---|---|")
+	for _, c := range rows {
+		fmt.Fprintf(w, "| %s | %s | %s |\n", mdStatus(c.OK, diag), mdCell(c.Name), mdCell(c.Detail))
+	}
+}
+
+func renderMarkdown(w io.Writer, r jsonResult) {
+	fmt.Fprintln(w, "# vault_doctor medic report")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "**%s**\n", summaryText(r.Failures))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "| | |")
+	fmt.Fprintln(w, "|---|---|")
+	for _, m := range reportMeta(r) {
+		fmt.Fprintf(w, "| %s | %s |\n", mdCell(m[0]), mdCell(m[1]))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "## Checks")
+	fmt.Fprintln(w)
+	mdTable(w, r.Checks, false)
+
+	if len(r.Hints) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "## Next actions")
+		fmt.Fprintln(w)
+		for _, h := range r.Hints {
+			fmt.Fprintf(w, "- %s\n", h)
+		}
+	}
+	if len(r.Diagnostics) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "## Diagnostics")
+		fmt.Fprintln(w)
+		mdTable(w, r.Diagnostics, true)
+	}
+	if len(r.Telemetry) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "## Telemetry")
+		fmt.Fprintln(w)
+		mdTable(w, r.Telemetry, true)
+	}
+	if len(r.Namespaces) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "## Namespaces (%d)\n", len(r.Namespaces))
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| Namespace | Mounts | Auth | Policies | Quotas | Flagged |")
+		fmt.Fprintln(w, "|---|---|---|---|---|---|")
+		for _, n := range r.Namespaces {
+			fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d |\n", mdCell(n.Path),
+				n.Counts.Mounts, n.Counts.AuthMethods, n.Counts.Policies, n.Counts.Quotas, n.Flagged)
+		}
+		if t := r.NamespaceTotals; t != nil {
+			fmt.Fprintf(w, "| **Total** | %d | %d | %d | %d | |\n", t.Mounts, t.AuthMethods, t.Policies, t.Quotas)
+		}
+	}
+}
+
+// ---- HTML ----
+
+// Single self-contained page: inline CSS, no scripts or external assets.
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"status": func(ok, diag bool) string {
+		switch {
+		case ok:
+			return "ok"
+		case diag:
+			return "warn"
+		}
+		return "fail"
+	},
+	"label": func(ok, diag bool) string {
+		switch {
+		case ok:
+			return "OK"
+		case diag:
+			return "WARN"
+		}
+		return "FAIL"
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>vault_doctor medic report{{with .R.ClusterName}} — {{.}}{{end}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;margin:2em auto;max-width:1100px;color:#1f2328;padding:0 1em}
+h1{font-size:1.6em;margin-bottom:.2em}
+h2{font-size:1.2em;margin-top:1.8em;border-bottom:1px solid #d0d7de;padding-bottom:.2em}
+table{border-collapse:collapse;width:100%;font-size:.92em}
+th,td{text-align:left;padding:.35em .6em;border-bottom:1px solid #eaeef2;vertical-align:top}
+th{background:#f6f8fa}
+td.detail{font-family:ui-monospace,SFMono-Regular,Menlo,monospace;word-break:break-word}
+table.meta{width:auto}
+table.meta th{background:none;font-weight:600;padding-right:2em}
+.badge{display:inline-block;min-width:3.2em;text-align:center;border-radius:4px;padding:.05em .4em;font-size:.8em;font-weight:700;color:#fff}
+.ok .badge{background:#1a7f37}
+.warn .badge{background:#bf8700}
+.fail .badge{background:#cf222e}
+tr.fail td{background:#fff5f5}
+tr.warn td{background:#fffbea}
+.summary{font-weight:600;padding:.5em .8em;border-radius:6px;display:inline-block}
+.summary.ok{background:#dafbe1;color:#1a7f37}
+.summary.fail{background:#ffebe9;color:#cf222e}
+ul.hints li{margin:.3em 0}
+</style>
+</head>
+<body>
+<h1>vault_doctor medic report</h1>
+<p class="summary {{if eq .R.Failures 0}}ok{{else}}fail{{end}}">{{.Summary}}</p>
+<table class="meta">
+{{range .Meta}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+
+<h2>Checks</h2>
+<table>
+<tr><th>Status</th><th>Check</th><th>Detail</th></tr>
+{{range .R.Checks}}<tr class="{{status .OK false}}"><td><span class="badge">{{label .OK false}}</span></td><td>{{.Name}}</td><td class="detail">{{.Detail}}</td></tr>
+{{end}}</table>
+{{if .R.Hints}}
+<h2>Next actions</h2>
+<ul class="hints">
+{{range .R.Hints}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}{{if .R.Diagnostics}}
+<h2>Diagnostics</h2>
+<table>
+<tr><th>Status</th><th>Diagnostic</th><th>Detail</th></tr>
+{{range .R.Diagnostics}}<tr class="{{status .OK true}}"><td><span class="badge">{{label .OK true}}</span></td><td>{{.Name}}</td><td class="detail">{{.Detail}}</td></tr>
+{{end}}</table>
+{{end}}{{if .R.Telemetry}}
+<h2>Telemetry</h2>
+<table>
+<tr><th>Status</th><th>Indicator</th><th>Value</th></tr>
+{{range .R.Telemetry}}<tr class="{{status .OK true}}"><td><span class="badge">{{label .OK true}}</span></td><td>{{.Name}}</td><td class="detail">{{.Detail}}</td></tr>
+{{end}}</table>
+{{end}}{{if .R.Namespaces}}
+<h2>Namespaces ({{len .R.Namespaces}})</h2>
+<table>
+<tr><th>Namespace</th><th>Mounts</th><th>Auth</th><th>Policies</th><th>Quotas</th><th>Flagged</th></tr>
+{{range .R.Namespaces}}<tr class="{{if .Flagged}}warn{{else}}ok{{end}}"><td>{{.Path}}</td><td>{{.Counts.Mounts}}</td><td>{{.Counts.AuthMethods}}</td><td>{{.Counts.Policies}}</td><td>{{.Counts.Quotas}}</td><td>{{.Flagged}}</td></tr>
+{{end}}{{with .R.NamespaceTotals}}<tr><th>Total</th><th>{{.Mounts}}</th><th>{{.AuthMethods}}</th><th>{{.Policies}}</th><th>{{.Quotas}}</th><th></th></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+func renderHTML(w io.Writer, r jsonResult) {
+	summary := summaryText(r.Failures)
+	_ = htmlReport.Execute(w, struct {
+		R       jsonResult
+		Meta    [][2]string
+		Summary string
+	}{r, reportMeta(r), summary})
+}
@@ -0,0 +1,234 @@
+package medic
+
+import "fmt"
+
+// SchemaVersion versions the JSON report. Additive changes bump the minor;
+// removing or re-purposing a field bumps the major.
+const SchemaVersion = "1.1"
+
+// Severities in the JSON report.
+const (
+	sevOK       = "ok"
+	sevWarning  = "warning"
+	sevCritical = "critical"
+	sevSkipped  = "skipped"
+)
+
+// Per-item checks ("Mount kv/") share an ID; the subject goes into data
+// under this key.
+var checkSubjects = map[string]string{
+	"seal.backend":           "name",
+	"mount.entry":            "path",
+	"auth.method":            "path",
+	"auth.approle":           "path",
+	"kv.config":              "mount",
+	"lease.mount":            "mount",
+	"quota.lease_count":      "name",
+	"quota.rate_limit":       "name",
+	"clients.namespace":      "namespace",
+	"host.disk":              "path",
+	"plugin.upgrade_blocker": "path",
+	"plugin.pin":             "plugin",
+	"plugin.entry":           "plugin",
+}
+
+// itemCheck builds a per-item check, recording its subject as data.
+func itemCheck(id, subject, name string, ok bool, detail string) check {
+	return check{id, name, ok, detail, map[string]any{checkSubjects[id]: subject}}
+}
+
+// severity grades a check; failSev is what a failing check in this section
+// means (critical for results, warning for diagnostics). A failing check is
+// never skipped, even when its detail reads like one.
+func severity(c check, failSev string) string {
+	switch {
+	case !c.ok:
+		return failSev
+	case skippedDetail(c.detail):
+		return sevSkipped
+	}
+	return sevOK
+}
+
+// with returns c with data merged into its structured values, so consumers
+// of the JSON report need not parse detail strings.
+func (c check) with(data map[string]any) check {
+	merged := make(map[string]any, len(c.data)+len(data))
+	for k, v := range c.data {
+		merged[k] = v
+	}
+	for k, v := range data {
+		merged[k] = v
+	}
+	c.data = merged
+	return c
+}
+
+func toJSONCheck(c check, failSev string) jsonCheck {
+	return jsonCheck{ID: c.id, Name: c.name, OK: c.ok, Severity: severity(c, failSev), Detail: c.detail, Data: c.data}
+}
+
+// PrintSchema writes the JSON Schema of the medic JSON report.
+func PrintSchema() {
+	fmt.Print(reportSchema)
+}
+
+const reportSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://github.com/raymonepping/vault_doctor/schema/report-1.json",
+  "title": "vault_doctor medic report",
+  "description": "Output of 'vault_doctor medic --format json'. schema_version follows major.minor: minor versions only add fields.",
+  "type": "object",
+  "required": ["schema_version", "version", "timestamp", "checks", "failures"],
+  "properties": {
+    "schema_version": { "type": "string", "pattern": "^1\\.[0-9]+$" },
+    "version": { "type": "string", "description": "vault_doctor version" },
+    "timestamp": { "type": "integer", "description": "Unix seconds when the report was produced" },
+    "duration_ms": { "type": "number", "description": "Wall time of the whole run" },
+    "mode": { "type": "string", "description": "Health mode derived from sys/health (active, standby, perf-standby, sealed, ...)" },
+    "http_status": { "type": "integer" },
+    "cluster_name": { "type": "string" },
+    "leader_address": { "type": "string" },
+    "leader_is_self": { "type": "boolean" },
+    "seal_type": { "type": "string" },
+    "seal_threshold": { "type": "string", "description": "t/n for Shamir seals" },
+    "seal_progress": { "type": "integer" },
+    "storage_type": { "type": "string" },
+    "ha_enabled": { "type": "boolean" },
+    "node_count": { "type": "integer" },
+    "token_ttl": { "type": "string" },
+    "token_renewable": { "type": "boolean" },
+    "token_orphan": { "type": "boolean" },
+    "client_counts": { "$ref": "#/$defs/clientCounts" },
+    "host": { "$ref": "#/$defs/host" },
+    "namespaces": { "type": "array", "items": { "$ref": "#/$defs/namespace" } },
+    "namespace_totals": { "$ref": "#/$defs/nsCounts" },
+    "checks": { "type": "array", "items": { "$ref": "#/$defs/check" }, "description": "Pass/fail checks; any failure makes the exit code 1" },
+    "diagnostics": { "type": "array", "items": { "$ref": "#/$defs/check" }, "description": "Informational diagnostics; failures are warnings" },
+    "telemetry": { "type": "array", "items": { "$ref": "#/$defs/check" } },
+    "timings": { "type": "array", "items": { "$ref": "#/$defs/timing" }, "description": "Since 1.1: one entry per timed step, with its HTTP calls" },
+    "hints": { "type": "array", "items": { "type": "string" } },
+    "failures": { "type": "integer", "minimum": 0 }
+  },
+  "$defs": {
+    "check": {
+      "type": "object",
+      "required": ["id", "name", "ok", "severity"],
+      "properties": {
+        "id": { "type": "string", "pattern": "^[a-z_]+(\\.[A-Za-z0-9_-]+)+$", "description": "Stable identifier; per-item checks share an ID and carry the subject in data" },
+        "name": { "type": "string", "description": "Display name; may be reworded between releases" },
+        "ok": { "type": "boolean" },
+        "severity": { "enum": ["ok", "warning", "critical", "skipped"] },
+        "detail": { "type": "string", "description": "Human-readable detail; not stable" },
+        "step": { "type": "string", "description": "Since 1.1: the timed step that produced this check" },
+        "duration_ms": { "type": "number", "description": "Wall time of the sub-diagnostic that produced this check, or of its step when the step is not split" },
+        "data": { "type": "object", "description": "Structured values for this check (counts as integers, subject path/name, ...)" }
+      }
+    },
+    "timing": {
+      "type": "object",
+      "required": ["step", "section", "duration_ms", "checks"],
+      "properties": {
+        "step": { "type": "string" },
+        "section": { "enum": ["checks", "diagnostics", "telemetry"] },
+        "duration_ms": { "type": "number" },
+        "checks": { "type": "integer", "description": "Number of checks the step produced" },
+        "calls": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "required": ["method", "path", "total_ms"],
+            "properties": {
+              "method": { "type": "string" },
+              "path": { "type": "string" },
+              "status": { "type": "integer" },
+              "dns_ms": { "type": "number" },
+              "connect_ms": { "type": "number" },
+              "tls_ms": { "type": "number" },
+              "ttfb_ms": { "type": "number", "description": "From request start to the first response byte" },
+              "total_ms": { "type": "number", "description": "Until the response body was closed" },
+              "reused_conn": { "type": "boolean", "description": "Keep-alive reuse: no dns/connect/tls phase" },
+              "error": { "type": "string" }
+            }
+          }
+        }
+      }
+    },
+    "nsCounts": {
+      "type": "object",
+      "properties": {
+        "mounts": { "type": "integer" },
+        "auth_methods": { "type": "integer" },
+        "policies": { "type": "integer" },
+        "quotas": { "type": "integer" }
+      }
+    },
+    "namespace": {
+      "type": "object",
+      "required": ["path", "counts", "flagged"],
+      "properties": {
+        "path": { "type": "string" },
+        "counts": { "$ref": "#/$defs/nsCounts" },
+        "flagged": { "type": "integer" },
+        "diagnostics": { "type": "array", "items": { "$ref": "#/$defs/check" } }
+      }
+    },
+    "clientCounts": {
+      "type": "object",
+      "properties": {
+        "period_start": { "type": "string" },
+        "period_end": { "type": "string" },
+        "clients": { "type": "integer" },
+        "entity_clients": { "type": "integer" },
+        "non_entity_clients": { "type": "integer" },
+        "secret_syncs": { "type": "integer" },
+        "acme_clients": { "type": "integer" },
+        "month_clients": { "type": "integer" },
+        "licensed_clients": { "type": "integer" },
+        "utilisation_pct": { "type": "number" },
+        "namespaces": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "namespace": { "type": "string" },
+              "clients": { "type": "integer" },
+              "entity_clients": { "type": "integer" },
+              "non_entity_clients": { "type": "integer" },
+              "secret_syncs": { "type": "integer" },
+              "acme_clients": { "type": "integer" },
+              "mounts": { "type": "object", "additionalProperties": { "type": "integer" } }
+            }
+          }
+        }
+      }
+    },
+    "host": {
+      "type": "object",
+      "properties": {
+        "hostname": { "type": "string" },
+        "os": { "type": "string" },
+        "uptime_seconds": { "type": "integer" },
+        "cpu_count": { "type": "integer" },
+        "cpu_model": { "type": "string" },
+        "mem_total_bytes": { "type": "integer" },
+        "mem_available_bytes": { "type": "integer" },
+        "mem_used_percent": { "type": "number" },
+        "disks": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "path": { "type": "string" },
+              "fstype": { "type": "string" },
+              "total_bytes": { "type": "integer" },
+              "free_bytes": { "type": "integer" },
+              "used_percent": { "type": "number" }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
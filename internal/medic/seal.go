@@ -0,0 +1,128 @@
+package medic
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type sealStatusResp struct {
+	Type             string `json:"type"`
+	Threshold        int    `json:"t"`
+	N                int    `json:"n"`
+	Progress         int    `json:"progress"`
+	Migration        bool   `json:"migration"`
+	RecoverySeal     bool   `json:"recovery_seal"`
+	RecoverySealType string `json:"recovery_seal_type"`
+	BuildDate        string `json:"build_date"`
+	StorageType      string `json:"storage_type"`
+}
+
+type sealBackend struct {
+	Name           string `json:"name"`
+	Healthy        bool   `json:"healthy"`
+	UnhealthySince string `json:"unhealthy_since"`
+	LastSeen       string `json:"last_seen"`
+}
+
+type sealBackendStatus struct {
+	Healthy        bool          `json:"healthy"`
+	UnhealthySince string        `json:"unhealthy_since"`
+	Backends       []sealBackend `json:"backends"`
+}
+
+// sys/seal-backend-status answers at the top level; some proxies/versions wrap it in data
+type sealBackendStatusResp struct {
+	sealBackendStatus
+	Data *sealBackendStatus `json:"data"`
+}
+
+// kmsHint tailors the "KMS unreachable" advice to the seal type.
+func kmsHint(sealType, backend string) string {
+	t := strings.ToLower(sealType + " " + backend)
+	switch {
+	case strings.Contains(t, "awskms"):
+		return "AWS KMS seal unhealthy: check IAM credentials/role, kms:Encrypt/Decrypt/DescribeKey permissions, the KMS VPC endpoint or egress, and that the key is not disabled or pending deletion."
+	case strings.Contains(t, "azurekeyvault"):
+		return "Azure Key Vault seal unhealthy: check the managed identity or client secret, key permissions (wrapKey/unwrapKey), network rules/private endpoint and key expiry."
+	case strings.Contains(t, "gcpckms"):
+		return "GCP Cloud KMS seal unhealthy: check the service account (cloudkms.cryptoKeyEncrypterDecrypter), Private Google Access/egress and the key version state."
+	case strings.Contains(t, "transit"):
+		return "Transit seal unhealthy: check that the upstream Vault is reachable and unsealed, and that the transit token is valid and renewing (use a periodic token)."
+	case strings.Contains(t, "pkcs11"), strings.Contains(t, "hsm"):
+		return "HSM seal unhealthy: check HSM connectivity, the PKCS#11 library path, slot/PIN, and HSM partition health."
+	case strings.Contains(t, "ocikms"):
+		return "OCI KMS seal unhealthy: check the instance principal/API key, the crypto endpoint and vault state."
+	case strings.Contains(t, "kmip"):
+		return "KMIP seal unhealthy: check KMIP server reachability and the client certificate."
+	}
+	return "Seal backend unhealthy: check connectivity and credentials for the seal's key service."
+}
+
+func sealDiagnostics(client *http.Client, cfg Config) []check {
+	diagnostics := []check{}
+	var ss sealStatusResp
+	if code, err := doGET(client, cfg, "/v1/sys/seal-status", &ss); err == nil && code == 200 {
+		jsonSealType = ss.Type
+		sealStorageType = strings.ToLower(ss.StorageType)
+		autoUnseal := ss.RecoverySeal || (ss.Threshold == 0 && ss.N == 0)
+		sealData := map[string]any{"type": ss.Type, "threshold": ss.Threshold, "shares": ss.N, "recovery_seal": ss.RecoverySeal}
+		if autoUnseal {
+			jsonSealThresh = ""
+			jsonSealProg = nil
+			diagnostics = append(diagnostics, check{"seal.type", "Seal type", true, ss.Type, sealData})
+			if ss.RecoverySeal && ss.N > 0 {
+				rt := ss.RecoverySealType
+				if rt == "" {
+					rt = "shamir"
+				}
+				diagnostics = append(diagnostics, check{"seal.recovery_keys", "Recovery keys", true, fmt.Sprintf("threshold %d/%d (%s)", ss.Threshold, ss.N, rt), nil})
+			}
+		} else {
+			jsonSealThresh = fmt.Sprintf("%d/%d", ss.Threshold, ss.N)
+			jsonSealProg = &ss.Progress
+			diagnostics = append(diagnostics, check{"seal.type", "Seal type", true, fmt.Sprintf("%s (threshold %s, progress %d)", ss.Type, jsonSealThresh, ss.Progress), sealData})
+		}
+		if ss.Migration {
+			diagnostics = append(diagnostics, check{"seal.migration", "Seal migration", false, "in progress", nil})
+			extraHints = append(extraHints, "Seal migration in progress: finish it on every node ('vault operator unseal -migrate') before restarting or upgrading.")
+		}
+		if ss.BuildDate != "" {
+			diagnostics = append(diagnostics, check{"build.date", "Build date", true, ss.BuildDate, nil})
+		}
+	}
+
+	// Seal HA (multiple seal backends); 404 on versions without it
+	var sb sealBackendStatusResp
+	if code, err := doGET(client, cfg, "/v1/sys/seal-backend-status", &sb); err == nil && code == 200 {
+		st := sb.sealBackendStatus
+		if sb.Data != nil {
+			st = *sb.Data
+		}
+		detail := "healthy"
+		if !st.Healthy {
+			detail = "unhealthy"
+			if st.UnhealthySince != "" {
+				detail += " since " + st.UnhealthySince
+			}
+		}
+		diagnostics = append(diagnostics, check{"seal.backends", "Seal backends", st.Healthy, fmt.Sprintf("%s (%d backend(s))", detail, len(st.Backends)), nil})
+		for _, b := range st.Backends {
+			d := "healthy"
+			if !b.Healthy {
+				d = "unhealthy"
+				if b.UnhealthySince != "" {
+					d += " since " + b.UnhealthySince
+				}
+				extraHints = append(extraHints, kmsHint(jsonSealType, b.Name))
+			}
+			if b.LastSeen != "" {
+				d += ", last seen " + b.LastSeen
+			}
+			diagnostics = append(diagnostics, itemCheck("seal.backend", b.Name, "Seal backend "+b.Name, b.Healthy, d))
+		}
+	} else if code == 403 {
+		diagnostics = append(diagnostics, check{"seal.backends", "Seal backends", true, "forbidden (insufficient perms)", nil})
+	}
+	return diagnostics
+}
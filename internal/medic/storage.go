@@ -0,0 +1,171 @@
+package medic
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Backends that support HA coordination on their own.
+var haCapableStorage = map[string]bool{
+	"raft":        true,
+	"consul":      true,
+	"etcd":        true,
+	"zookeeper":   true,
+	"dynamodb":    true,
+	"spanner":     true,
+	"gcs":         true,
+	"mysql":       true,
+	"postgresql":  true,
+	"cockroachdb": true,
+}
+
+type raftConfigResp struct {
+	Data struct {
+		Config struct {
+			Servers []struct {
+				NodeID  string `json:"node_id"`
+				Address string `json:"address"`
+				Leader  bool   `json:"leader"`
+				Voter   bool   `json:"voter"`
+			} `json:"servers"`
+		} `json:"config"`
+	} `json:"data"`
+}
+
+type autopilotStateResp struct {
+	Data struct {
+		Healthy          bool     `json:"healthy"`
+		FailureTolerance int      `json:"failure_tolerance"`
+		Leader           string   `json:"leader"`
+		Voters           []string `json:"voters"`
+	} `json:"data"`
+}
+
+// detectStorage identifies the storage backend, preferring the sanitized
+// config and falling back to /sys/seal-status and raft markers in /sys/leader.
+func detectStorage() (backend, ha, source string) {
+	if serverConfig != nil && serverConfig.Data.Storage != nil {
+		backend = strings.ToLower(serverConfig.Data.Storage.Type)
+		if serverConfig.Data.HAStorage != nil {
+			ha = strings.ToLower(serverConfig.Data.HAStorage.Type)
+		}
+		return backend, ha, "config"
+	}
+	if sealStorageType != "" {
+		return sealStorageType, "", "seal status"
+	}
+	if leaderRaftIndex > 0 {
+		return "raft", "", "leader raft index"
+	}
+	return "", "", ""
+}
+
+// productionLike guesses whether a node is meant to serve real traffic:
+// Enterprise builds, or TLS on a non-loopback address.
+func productionLike(cfg Config, health *healthResp) bool {
+	if health != nil && health.Enterprise {
+		return true
+	}
+	u, err := url.Parse(cfg.Addr)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return false
+	}
+	return true
+}
+
+func storageDiagnostics(client *http.Client, cfg Config, health *healthResp) []check {
+	diagnostics := []check{}
+	backend, haBackend, source := detectStorage()
+	prod := productionLike(cfg, health)
+	jsonStorage = backend
+
+	if backend == "" {
+		diagnostics = append(diagnostics, check{"storage.backend", "Storage backend", true, "unknown (sanitized config not readable)", nil})
+	} else {
+		diagnostics = append(diagnostics, check{"storage.backend", "Storage backend", true, fmt.Sprintf("%s (from %s)", backend, source), nil})
+		if haBackend != "" {
+			diagnostics = append(diagnostics, check{"storage.ha_backend", "HA storage", true, haBackend, nil})
+		}
+	}
+
+	if backend == "inmem" {
+		ok := !prod
+		diagnostics = append(diagnostics, check{"storage.dev_mode", "Dev-mode storage", ok, "inmem: all data is lost on restart", nil})
+		if !ok {
+			extraHints = append(extraHints, "This node looks production-like but uses inmem storage (dev mode). Move to integrated storage (raft) before storing real secrets.")
+		}
+	}
+
+	if leaderHA != nil {
+		jsonHAEnabled = leaderHA
+		ok := *leaderHA || !prod
+		detail := fmt.Sprintf("%v", *leaderHA)
+		if !*leaderHA && backend != "" && !haCapableStorage[backend] && haBackend == "" {
+			detail += fmt.Sprintf(" (%s storage has no HA support)", backend)
+		}
+		diagnostics = append(diagnostics, check{"ha.enabled", "HA enabled", ok, detail, nil})
+		if !ok {
+			extraHints = append(extraHints, "HA is disabled on a production-like node; a single failure takes Vault down. Use raft with 3 or 5 voters, or add ha_storage.")
+		}
+	}
+
+	if backend == "raft" {
+		diagnostics = append(diagnostics, raftDiagnostics(client, cfg, prod)...)
+	} else if backend != "" {
+		diagnostics = append(diagnostics, check{"raft.checks", "Raft checks", true, fmt.Sprintf("skipped (storage=%s)", backend), nil})
+	}
+	return diagnostics
+}
+
+// Integrated storage peers and autopilot health (raft only).
+func raftDiagnostics(client *http.Client, cfg Config, prod bool) []check {
+	diagnostics := []check{}
+
+	var rc raftConfigResp
+	if code, err := doGET(client, cfg, "/v1/sys/storage/raft/configuration", &rc); err == nil && code == 200 {
+		servers := rc.Data.Config.Servers
+		voters := 0
+		for _, s := range servers {
+			if s.Voter {
+				voters++
+			}
+		}
+		n := len(servers)
+		jsonNodeCount = &n
+		ok := !prod || voters >= 3
+		detail := fmt.Sprintf("%d (voters=%d)", n, voters)
+		if voters > 0 && voters%2 == 0 {
+			detail += " — even voter count adds no fault tolerance"
+			ok = false
+		}
+		diagnostics = append(diagnostics, check{"raft.peers", "Raft peers", ok, detail, map[string]any{"nodes": n, "voters": voters}})
+		if prod && voters < 3 {
+			extraHints = append(extraHints, fmt.Sprintf("Raft cluster has %d voter(s); run 3 or 5 voters to survive a node failure.", voters))
+		}
+	} else if code == 403 {
+		diagnostics = append(diagnostics, check{"raft.peers", "Raft peers", true, "forbidden (insufficient perms)", nil})
+	}
+
+	var ap autopilotStateResp
+	if code, err := doGET(client, cfg, "/v1/sys/storage/raft/autopilot/state", &ap); err == nil && code == 200 {
+		diagnostics = append(diagnostics, check{"raft.autopilot", "Raft autopilot", ap.Data.Healthy,
+			fmt.Sprintf("healthy=%v failure_tolerance=%d", ap.Data.Healthy, ap.Data.FailureTolerance),
+			map[string]any{"healthy": ap.Data.Healthy, "failure_tolerance": ap.Data.FailureTolerance}})
+		if !ap.Data.Healthy {
+			extraHints = append(extraHints, "Raft autopilot reports the cluster unhealthy; check 'vault operator raft autopilot state' for failing servers.")
+		}
+	} else if code == 403 {
+		diagnostics = append(diagnostics, check{"raft.autopilot", "Raft autopilot", true, "forbidden (insufficient perms)", nil})
+	}
+	return diagnostics
+}
@@ -0,0 +1,99 @@
+package medic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// user template for --format template, parsed up front by Run
+var reportTemplate *template.Template
+
+var colourCodes = map[string]string{
+	"red":    colRed,
+	"green":  colGreen,
+	"yellow": colYellow,
+}
+
+// templateFuncs exposes the pretty renderer's helpers to user templates.
+func templateFuncs(opt Options) template.FuncMap {
+	code := func(name string) string {
+		return colourCodes[strings.ToLower(name)]
+	}
+	return template.FuncMap{
+		// {{cwrap .Name "red"}} mirrors cwrap; {{.Name | colour "red"}} pipes
+		"cwrap": func(s, colour string) string {
+			if c := code(colour); c != "" {
+				return cwrap(s, c, opt)
+			}
+			return s
+		},
+		"colour": func(colour, s string) string {
+			if c := code(colour); c != "" {
+				return cwrap(s, c, opt)
+			}
+			return s
+		},
+		// {{.Name | pad 30}}: right-pad to width (never truncates)
+		"pad": func(width int, s string) string {
+			if len(s) < width {
+				return s + strings.Repeat(" ", width-len(s))
+			}
+			return s
+		},
+		"humanTTL": func(v any) string {
+			switch n := v.(type) {
+			case int:
+				return humanTTL(int64(n))
+			case int64:
+				return humanTTL(n)
+			case float64:
+				return humanTTL(int64(n))
+			}
+			return fmt.Sprint(v)
+		},
+		"nameColWidth": func(cs []jsonCheck) int {
+			rows := make([]check, 0, len(cs))
+			for _, c := range cs {
+				rows = append(rows, check{name: c.Name})
+			}
+			return nameColWidth(rows)
+		},
+		"mark": func(c jsonCheck) string {
+			switch c.Severity {
+			case sevCritical:
+				return "❌"
+			case sevWarning:
+				return "!"
+			}
+			return "✅"
+		},
+		"summary": summaryText,
+		"join":    strings.Join,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}
+}
+
+func loadReportTemplate(path string, opt Options) (*template.Template, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("template: --format template needs --template FILE")
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(filepath.Base(path)).Funcs(templateFuncs(opt)).Parse(string(body))
+}
+
+func renderTemplate(w io.Writer, t *template.Template, r jsonResult) error {
+	return t.Execute(w, r)
+}
@@ -0,0 +1,229 @@
+package medic
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	slowStepWarnMS = 1000.0 // steps slower than this are flagged
+	slowestShown   = 5
+)
+
+// One HTTP call to Vault, with httptrace phases. dns/connect/tls are zero
+// when a kept-alive connection was reused.
+type httpCall struct {
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Status    int     `json:"status,omitempty"`
+	DNSMS     float64 `json:"dns_ms"`
+	ConnectMS float64 `json:"connect_ms"`
+	TLSMS     float64 `json:"tls_ms"`
+	TTFBMS    float64 `json:"ttfb_ms"`
+	TotalMS   float64 `json:"total_ms"`
+	Reused    bool    `json:"reused_conn"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// A timed step: one group of checks and the HTTP calls it made.
+type jsonTiming struct {
+	Step       string     `json:"step"`
+	Section    string     `json:"section"`
+	DurationMS float64    `json:"duration_ms"`
+	Checks     int        `json:"checks"`
+	Calls      []httpCall `json:"calls,omitempty"`
+}
+
+var (
+	callsMu   sync.Mutex
+	httpCalls []httpCall
+
+	stepTimings []jsonTiming
+	// section + "\x00" + checkKey -> the step and duration that produced it
+	checkSteps = map[string]checkTiming{}
+)
+
+type checkTiming struct {
+	step       int // index into stepTimings
+	durationMS float64
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()/100) / 10
+}
+
+// tracingTransport records every request's DNS, connect, TLS and
+// time-to-first-byte, finishing the record when the body is closed.
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := httpCall{Method: req.Method, Path: req.URL.RequestURI()}
+	start := time.Now()
+	var dnsStart, connStart, tlsStart time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { call.DNSMS = ms(time.Since(dnsStart)) },
+		ConnectStart:      func(string, string) { connStart = time.Now() },
+		ConnectDone:       func(string, string, error) { call.ConnectMS = ms(time.Since(connStart)) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { call.TLSMS = ms(time.Since(tlsStart)) },
+		GotConn:           func(i httptrace.GotConnInfo) { call.Reused = i.Reused },
+		GotFirstResponseByte: func() {
+			call.TTFBMS = ms(time.Since(start))
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		call.TotalMS = ms(time.Since(start))
+		call.Error = err.Error()
+		recordCall(call)
+		return nil, err
+	}
+	call.Status = res.StatusCode
+	res.Body = &tracedBody{ReadCloser: res.Body, done: func() {
+		call.TotalMS = ms(time.Since(start))
+		recordCall(call)
+	}}
+	return res, nil
+}
+
+type tracedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+func recordCall(c httpCall) {
+	callsMu.Lock()
+	httpCalls = append(httpCalls, c)
+	callsMu.Unlock()
+}
+
+type stepTimer struct {
+	section, name string
+	start         time.Time
+	from, calls   int
+	parts         map[string]float64 // checkKey -> duration of its sub-diagnostic
+}
+
+// startStep begins timing a step; from is the length of the check slice the
+// step appends to, so done can tell which checks it produced.
+func startStep(section, name string, from int) *stepTimer {
+	callsMu.Lock()
+	n := len(httpCalls)
+	callsMu.Unlock()
+	return &stepTimer{section: section, name: name, start: time.Now(), from: from, calls: n}
+}
+
+func (t *stepTimer) done(all []check) {
+	callsMu.Lock()
+	calls := append([]httpCall(nil), httpCalls[t.calls:]...)
+	callsMu.Unlock()
+
+	produced := []check{}
+	if t.from <= len(all) {
+		produced = all[t.from:]
+	}
+	stepTimings = append(stepTimings, jsonTiming{
+		Step:       t.name,
+		Section:    t.section,
+		DurationMS: ms(time.Since(t.start)),
+		Checks:     len(produced),
+		Calls:      calls,
+	})
+	for _, c := range produced {
+		k := checkKey(c.id, c.name, c.data)
+		d, ok := t.parts[k]
+		if !ok {
+			d = stepTimings[len(stepTimings)-1].DurationMS
+		}
+		checkSteps[t.section+"\x00"+k] = checkTiming{step: len(stepTimings) - 1, durationMS: d}
+	}
+}
+
+// part times one sub-diagnostic of a step (mounts within the namespace
+// diagnostics, say); the checks passed to the returned func report its
+// duration rather than the whole step's. A nil step times nothing.
+func (t *stepTimer) part() func(produced []check) {
+	if t == nil {
+		return func([]check) {}
+	}
+	start := time.Now()
+	return func(produced []check) {
+		d := ms(time.Since(start))
+		if t.parts == nil {
+			t.parts = map[string]float64{}
+		}
+		for _, c := range produced {
+			t.parts[checkKey(c.id, c.name, c.data)] = d
+		}
+	}
+}
+
+// stampSteps adds the producing step and its duration (or that of the
+// sub-diagnostic, when the step timed one) to report checks.
+func stampSteps(section string, cs []jsonCheck) {
+	for i := range cs {
+		if ct, ok := checkSteps[section+"\x00"+checkKey(cs[i].ID, cs[i].Name, cs[i].Data)]; ok {
+			cs[i].Step = stepTimings[ct.step].Step
+			cs[i].DurationMS = ct.durationMS
+		}
+	}
+}
+
+func slowestCall(calls []httpCall) *httpCall {
+	var worst *httpCall
+	for i := range calls {
+		if worst == nil || calls[i].TotalMS > worst.TotalMS {
+			worst = &calls[i]
+		}
+	}
+	return worst
+}
+
+func (c httpCall) String() string {
+	path := c.Path
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	s := fmt.Sprintf("%s %s %.1fms", c.Method, path, c.TotalMS)
+	if c.Reused {
+		return s + fmt.Sprintf(" (reused conn, ttfb %.1f)", c.TTFBMS)
+	}
+	return s + fmt.Sprintf(" (dns %.1f, connect %.1f, tls %.1f, ttfb %.1f)", c.DNSMS, c.ConnectMS, c.TLSMS, c.TTFBMS)
+}
+
+// slowestSteps is the "Slowest checks" section of the pretty output.
+func slowestSteps() []check {
+	steps := append([]jsonTiming(nil), stepTimings...)
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].DurationMS > steps[j].DurationMS })
+	if len(steps) > slowestShown {
+		steps = steps[:slowestShown]
+	}
+	rows := []check{}
+	for _, s := range steps {
+		detail := fmt.Sprintf("%.1fms, %d call(s)", s.DurationMS, len(s.Calls))
+		if w := slowestCall(s.Calls); w != nil {
+			detail += "; slowest " + w.String()
+		}
+		rows = append(rows, check{name: s.Step, ok: s.DurationMS < slowStepWarnMS, detail: detail})
+	}
+	return rows
+}
@@ -1,5 +1,7 @@
 package medic
 
+import "time"
+
 type Config struct {
 	Addr       string
 	Token      string
@@ -10,38 +12,57 @@ type Config struct {
 }
 
 type check struct {
+	id     string // stable ID in the JSON report; empty for display-only rows
 	name   string
 	ok     bool
 	detail string
+	data   map[string]any // structured values for the JSON report
 }
 
 // For JSON mode
 type jsonCheck struct {
-	Name   string `json:"name"`
-	OK     bool   `json:"ok"`
-	Detail string `json:"detail,omitempty"`
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	OK         bool           `json:"ok"`
+	Severity   string         `json:"severity"`
+	Detail     string         `json:"detail,omitempty"`
+	Step       string         `json:"step,omitempty"`
+	DurationMS float64        `json:"duration_ms,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
 }
 type jsonDiag = jsonCheck
 
+// Shape documented by reportSchema (vault_doctor schema); see SchemaVersion.
 type jsonResult struct {
-	Version        string `json:"version"`
-	Timestamp      int64  `json:"timestamp"`
-	Mode           string `json:"mode,omitempty"`
-	HTTPStatus     int    `json:"http_status,omitempty"`
-	ClusterName    string `json:"cluster_name,omitempty"`
-	LeaderAddress  string `json:"leader_address,omitempty"`
-	LeaderIsSelf   *bool  `json:"leader_is_self,omitempty"`
-	SealType       string `json:"seal_type,omitempty"`
-	SealThreshold  string `json:"seal_threshold,omitempty"`
-	SealProgress   *int   `json:"seal_progress,omitempty"`
-	TokenTTL       string `json:"token_ttl,omitempty"`
-	TokenRenewable *bool  `json:"token_renewable,omitempty"`
-	TokenOrphan    *bool  `json:"token_orphan,omitempty"`
-	// (we keep KV counts inside diagnostics; promote later if desired)
-	Checks      []jsonCheck `json:"checks"`
-	Diagnostics []jsonDiag  `json:"diagnostics,omitempty"`
-	Hints       []string    `json:"hints,omitempty"`
-	Failures    int         `json:"failures"`
+	SchemaVersion  string  `json:"schema_version"`
+	Version        string  `json:"version"`
+	Timestamp      int64   `json:"timestamp"`
+	DurationMS     float64 `json:"duration_ms,omitempty"`
+	Mode           string  `json:"mode,omitempty"`
+	HTTPStatus     int     `json:"http_status,omitempty"`
+	ClusterName    string  `json:"cluster_name,omitempty"`
+	LeaderAddress  string  `json:"leader_address,omitempty"`
+	LeaderIsSelf   *bool   `json:"leader_is_self,omitempty"`
+	SealType       string  `json:"seal_type,omitempty"`
+	SealThreshold  string  `json:"seal_threshold,omitempty"`
+	SealProgress   *int    `json:"seal_progress,omitempty"`
+	StorageType    string  `json:"storage_type,omitempty"`
+	HAEnabled      *bool   `json:"ha_enabled,omitempty"`
+	NodeCount      *int    `json:"node_count,omitempty"`
+	TokenTTL       string  `json:"token_ttl,omitempty"`
+	TokenRenewable *bool   `json:"token_renewable,omitempty"`
+	TokenOrphan    *bool   `json:"token_orphan,omitempty"`
+	// counts (mounts, KV engines, leases, ...) are in each diagnostic's data
+	ClientCounts    *jsonClientReport `json:"client_counts,omitempty"`
+	Host            *jsonHostInfo     `json:"host,omitempty"`
+	Namespaces      []jsonNamespace   `json:"namespaces,omitempty"`
+	NamespaceTotals *nsCounts         `json:"namespace_totals,omitempty"`
+	Checks          []jsonCheck       `json:"checks"`
+	Diagnostics     []jsonDiag        `json:"diagnostics,omitempty"`
+	Telemetry       []jsonDiag        `json:"telemetry,omitempty"`
+	Timings         []jsonTiming      `json:"timings,omitempty"`
+	Hints           []string          `json:"hints,omitempty"`
+	Failures        int               `json:"failures"`
 }
 
 // CLI options passed from main
@@ -50,4 +71,55 @@ type Options struct {
 	Quiet   bool
 	JSON    bool
 	NoColor bool
+
+	// Report format: pretty (default), json, markdown, html, junit, template
+	// or nagios.
+	// JSON is kept as the --json alias for Format "json".
+	Format string
+
+	// text/template file rendered for Format "template"
+	Template string
+
+	// Vault data directory (e.g. /opt/vault/data); marks the host disk that
+	// holds it. The sanitized config does not report storage.path.
+	DataPath string
+
+	// Licensed client limit used for the utilisation check; overrides the
+	// license (0 = read it from sys/license/status)
+	ClientLimit int
+
+	// Walk child namespaces and run namespace-scoped diagnostics in each
+	RecursiveNamespaces bool
+
+	// KV v2 policy thresholds (zero values disable the check)
+	KVMaxVersions    int
+	KVCASMounts      []string // mount globs that must have cas_required
+	KVDeleteAfterMax time.Duration
+
+	// Config rule IDs (e.g. VD-CFG-006) to skip
+	SuppressRules []string
+
+	// Drift detection: compare against / write a cluster shape snapshot
+	Baseline     string
+	SaveBaseline string
+
+	// Swap hostnames, IPs, cluster/namespace names and mount paths for
+	// stable pseudonyms (credentials are always redacted)
+	Anonymize bool
+
+	// Nagios thresholds as METRIC=RANGE (latency, token_ttl, seal_progress)
+	Warn []string
+	Crit []string
+
+	// Let warning diagnostics and telemetry raise the Nagios state to
+	// WARNING; by default only the thresholds above do
+	NagiosWarnDiagnostics bool
+
+	// Post a summary after the run; preset auto|generic|slack|teams.
+	// NotifyOnChange posts only when the status differs from the last run,
+	// remembered in NotifyState (default: a file in the user cache dir).
+	NotifyWebhook  string
+	NotifyPreset   string
+	NotifyOnChange bool
+	NotifyState    string
 }
@@ -13,7 +13,7 @@ import (
 )
 
 func promptUnseal(client *http.Client, cfg Config, opt Options) error {
-	if opt.Quiet || opt.JSON {
+	if opt.Quiet || opt.structured() {
 		return nil
 	}
 	fmt.Println()
@@ -36,6 +36,7 @@ func promptUnseal(client *http.Client, cfg Config, opt Options) error {
 		if key == "" {
 			break
 		}
+		registerSecret(key)
 		sealed, err := unsealOnce(client, cfg, key)
 		if err != nil {
 			return err